@@ -0,0 +1,109 @@
+// Package confaccount 实现账户模型的保密余额（PGC/Zether 风格）：
+// 每个账户在自己的公钥下保存余额密文 (X, Y) = (r·P, r·G + b·h)，转账时同态地扣减与增加。
+package confaccount
+
+import (
+	"errors"
+	"math/big"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/RangeProof"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+var (
+	ErrAccountExists  = errors.New("confaccount: account already registered")
+	ErrUnknownAccount = errors.New("confaccount: unknown account")
+	ErrBadNonce       = errors.New("confaccount: unexpected transfer nonce")
+	ErrSelfTransfer   = errors.New("confaccount: sender and receiver are the same account")
+)
+
+// Params 公共参数：金额加密参数与 64 位、聚合 2 个值的范围证明参数
+type Params struct {
+	Amount confamount.Params
+	Range  *rangeproof.Params
+}
+
+// NewParams 由金额加密参数构造账户参数
+func NewParams(amount confamount.Params) (*Params, error) {
+	rp, err := rangeproof.NewParams(amount.G, amount.H, 64, 2)
+	if err != nil {
+		return nil, err
+	}
+	return &Params{Amount: amount, Range: rp}, nil
+}
+
+// Account 链上账户状态
+type Account struct {
+	PK      twistededwards.PointAffine
+	Balance confamount.Ciphertext
+	Nonce   uint64 // 下一笔转出交易的序号
+}
+
+// Ledger 内存中的账户状态机，用于测试与演示；不做并发控制
+type Ledger struct {
+	params   *Params
+	accounts map[twistededwards.PointAffine]*Account
+}
+
+// NewLedger 创建空账本
+func NewLedger(params *Params) *Ledger {
+	return &Ledger{params: params, accounts: make(map[twistededwards.PointAffine]*Account)}
+}
+
+// Register 以零余额（单位元密文）注册账户
+func (l *Ledger) Register(pk twistededwards.PointAffine) error {
+	if _, ok := l.accounts[pk]; ok {
+		return ErrAccountExists
+	}
+	zero := confamount.EncryptWithRandomness(&l.params.Amount, &pk, big.NewInt(0), big.NewInt(0))
+	l.accounts[pk] = &Account{PK: pk, Balance: *zero}
+	return nil
+}
+
+// Account 返回账户当前状态的副本
+func (l *Ledger) Account(pk twistededwards.PointAffine) (Account, error) {
+	acc, ok := l.accounts[pk]
+	if !ok {
+		return Account{}, ErrUnknownAccount
+	}
+	return *acc, nil
+}
+
+// Deposit 公开金额入账：余额密文加上 (0, v·h)
+func (l *Ledger) Deposit(pk twistededwards.PointAffine, v uint64) error {
+	acc, ok := l.accounts[pk]
+	if !ok {
+		return ErrUnknownAccount
+	}
+	deposit := confamount.EncryptWithRandomness(&l.params.Amount, &pk, new(big.Int).SetUint64(v), big.NewInt(0))
+	acc.Balance = *confamount.Add(&acc.Balance, deposit)
+	return nil
+}
+
+// Apply 验证转账并更新双方余额：发送方减去 Sender，接收方加上 Receiver。
+// 证明针对发送方构造交易时的余额，期间若发送方收到转账，交易会验证失败，需重新构造。
+func (l *Ledger) Apply(tx *Transfer) error {
+	from, ok := l.accounts[tx.From]
+	if !ok {
+		return ErrUnknownAccount
+	}
+	to, ok := l.accounts[tx.To]
+	if !ok {
+		return ErrUnknownAccount
+	}
+	if from == to {
+		return ErrSelfTransfer
+	}
+	if tx.Nonce != from.Nonce {
+		return ErrBadNonce
+	}
+	if err := VerifyTransfer(l.params, &from.Balance, tx); err != nil {
+		return err
+	}
+	from.Balance = *confamount.Sub(&from.Balance, &tx.Sender)
+	to.Balance = *confamount.Add(&to.Balance, &tx.Receiver)
+	from.Nonce++
+	return nil
+}
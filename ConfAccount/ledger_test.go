@@ -0,0 +1,145 @@
+package confaccount
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// testLedger 账本与已注册的 alice、bob，alice 存入 100
+type testLedger struct {
+	params         *Params
+	ledger         *Ledger
+	aliceSK, bobSK *big.Int
+	alice, bob     twistededwards.PointAffine
+}
+
+func newTestLedger(t *testing.T) *testLedger {
+	t.Helper()
+	params, err := NewParams(confamount.DefaultParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := &testLedger{params: params, ledger: NewLedger(params)}
+	for _, acc := range []struct {
+		sk **big.Int
+		pk *twistededwards.PointAffine
+	}{{&tl.aliceSK, &tl.alice}, {&tl.bobSK, &tl.bob}} {
+		sk, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		*acc.sk = sk
+		acc.pk.ScalarMultiplication(&params.Amount.G, sk)
+		if err := tl.ledger.Register(*acc.pk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tl.ledger.Deposit(tl.alice, 100); err != nil {
+		t.Fatal(err)
+	}
+	return tl
+}
+
+// balance 解密账户余额
+func (tl *testLedger) balance(t *testing.T, sk *big.Int, pk twistededwards.PointAffine) uint64 {
+	t.Helper()
+	acc, err := tl.ledger.Account(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := confamount.Decrypt(&tl.params.Amount, sk, &acc.Balance, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// transfer 由 alice 按账本上的余额密文与声称的明文 b 构造转账
+func (tl *testLedger) transfer(t *testing.T, b, v uint64) *Transfer {
+	t.Helper()
+	acc, err := tl.ledger.Account(tl.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := NewTransfer(rand.Reader, tl.params, tl.aliceSK, &acc.Balance, b, &tl.bob, v, acc.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestApplyTransfer(t *testing.T) {
+	tl := newTestLedger(t)
+	if err := tl.ledger.Apply(tl.transfer(t, 100, 30)); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := tl.balance(t, tl.aliceSK, tl.alice), tl.balance(t, tl.bobSK, tl.bob); a != 70 || b != 30 {
+		t.Fatalf("balances alice %d, bob %d, want 70, 30", a, b)
+	}
+	if err := tl.ledger.Apply(tl.transfer(t, 70, 70)); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := tl.balance(t, tl.aliceSK, tl.alice), tl.balance(t, tl.bobSK, tl.bob); a != 0 || b != 100 {
+		t.Fatalf("balances alice %d, bob %d, want 0, 100", a, b)
+	}
+	if acc, _ := tl.ledger.Account(tl.alice); acc.Nonce != 2 {
+		t.Fatalf("nonce %d, want 2", acc.Nonce)
+	}
+}
+
+func TestApplyReplay(t *testing.T) {
+	tl := newTestLedger(t)
+	tx := tl.transfer(t, 100, 30)
+	if err := tl.ledger.Apply(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.ledger.Apply(tx); !errors.Is(err, ErrBadNonce) {
+		t.Fatalf("replay: got %v, want ErrBadNonce", err)
+	}
+	// 改写序号后证明不再成立
+	tx.Nonce++
+	if err := tl.ledger.Apply(tx); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("renumbered replay: got %v, want ErrInvalidTransfer", err)
+	}
+	if a, b := tl.balance(t, tl.aliceSK, tl.alice), tl.balance(t, tl.bobSK, tl.bob); a != 70 || b != 30 {
+		t.Fatalf("balances alice %d, bob %d, want 70, 30", a, b)
+	}
+}
+
+func TestApplyOverdraft(t *testing.T) {
+	tl := newTestLedger(t)
+	if _, err := NewTransfer(rand.Reader, tl.params, tl.aliceSK, &confamount.Ciphertext{}, 100, &tl.bob, 101, 0); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("v > b: got %v, want ErrInsufficientBalance", err)
+	}
+
+	// 按伪造的 1000 余额密文构造的转账不能从实际余额 100 中扣出 500
+	fake := confamount.EncryptWithRandomness(&tl.params.Amount, &tl.alice, big.NewInt(1000), big.NewInt(7))
+	tx, err := NewTransfer(rand.Reader, tl.params, tl.aliceSK, fake, 1000, &tl.bob, 500, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.ledger.Apply(tx); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("forged balance: got %v, want ErrInvalidTransfer", err)
+	}
+
+	// 两笔都针对余额 100 构造的 80 转账，第一笔生效后第二笔失败
+	first := tl.transfer(t, 100, 80)
+	second := tl.transfer(t, 100, 80)
+	second.Nonce = 1
+	if err := tl.ledger.Apply(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.ledger.Apply(second); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("second spend: got %v, want ErrInvalidTransfer", err)
+	}
+	if a, b := tl.balance(t, tl.aliceSK, tl.alice), tl.balance(t, tl.bobSK, tl.bob); a != 20 || b != 80 {
+		t.Fatalf("balances alice %d, bob %d, want 20, 80", a, b)
+	}
+}
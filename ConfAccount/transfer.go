@@ -0,0 +1,200 @@
+package confaccount
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/RangeProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// ownershipDomain 余额与私钥证明挑战的域分隔标签
+const ownershipDomain = "LYcode/ConfAccount/Ownership/v1"
+
+var (
+	ErrInsufficientBalance = errors.New("confaccount: insufficient balance")
+	ErrWrongBalance        = errors.New("confaccount: balance does not match the encrypted balance")
+	ErrInvalidTransfer     = errors.New("confaccount: invalid transfer proof")
+)
+
+// Transfer 一笔保密转账：金额 v 在双方公钥下以共享随机数加密，
+// 附明文相等证明、v 与剩余余额 b' 的聚合范围证明，以及发送方私钥与剩余余额的证明
+type Transfer struct {
+	From, To twistededwards.PointAffine
+	Nonce    uint64 // 发送方账户的转账序号，防止重放
+
+	Sender   confamount.Ciphertext // (r·P_from, r·G + v·h)
+	Receiver confamount.Ciphertext // (r·P_to, r·G + v·h)
+	Equality confamount.EqualityProof
+
+	Remaining twistededwards.PointAffine // Y' = r'·G + b'·h
+	Ownership OwnershipProof
+	Range     rangeproof.Proof // [Sender.Y, Remaining] ∈ [0, 2^64)
+}
+
+// OwnershipProof 对新余额密文 (X, Y) = 旧余额 − Sender 证明知道 s = sk^{-1}、b'、r' 使
+//
+//	G = s·P_from，Y = s·X + b'·h，Y' = r'·G + b'·h
+//
+// 第一式说明发送方持有私钥，后两式说明 Y' 承诺的正是解密后的剩余余额
+type OwnershipProof struct {
+	C  big.Int
+	Zs big.Int
+	Zb big.Int
+	Zr big.Int
+}
+
+// NewTransfer 由发送方私钥 sk、当前余额密文 balance 及其明文 b 构造向 to 转账 v 的交易
+func NewTransfer(rand io.Reader, params *Params, sk *big.Int, balance *confamount.Ciphertext, b uint64, to *twistededwards.PointAffine, v, nonce uint64) (*Transfer, error) {
+	if v > b {
+		return nil, ErrInsufficientBalance
+	}
+	hm, err := confamount.DecryptToPoint(sk, balance)
+	if err != nil {
+		return nil, err
+	}
+	expected := params.Amount.Commit(new(big.Int).SetUint64(b), big.NewInt(0))
+	if !hm.Equal(&expected) {
+		return nil, ErrWrongBalance
+	}
+
+	tx := &Transfer{To: *to, Nonce: nonce}
+	tx.From.ScalarMultiplication(&params.Amount.G, sk)
+
+	// 金额密文与明文相等证明
+	vb := new(big.Int).SetUint64(v)
+	pks := []twistededwards.PointAffine{tx.From, tx.To}
+	cts, r, err := confamount.EncryptShared(rand, &params.Amount, pks, vb)
+	if err != nil {
+		return nil, err
+	}
+	tx.Sender, tx.Receiver = cts[0], cts[1]
+	eq, err := confamount.ProveEquality(rand, &params.Amount, pks, cts, vb, []*big.Int{r})
+	if err != nil {
+		return nil, err
+	}
+	tx.Equality = *eq
+
+	// 剩余余额的新承诺与范围证明
+	rest := b - v
+	rPrime, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	tx.Remaining = params.Amount.Commit(new(big.Int).SetUint64(rest), rPrime)
+	rp, err := rangeproof.Prove(rand, params.Range, []uint64{v, rest}, []*big.Int{r, rPrime})
+	if err != nil {
+		return nil, err
+	}
+	tx.Range = *rp
+
+	// 私钥与剩余余额证明
+	curve := twistededwards.GetEdwardsCurve()
+	s := new(big.Int).ModInverse(sk, &curve.Order)
+	newBalance := confamount.Sub(balance, &tx.Sender)
+	own, err := proveOwnership(rand, params, tx, newBalance, s, new(big.Int).SetUint64(rest), rPrime)
+	if err != nil {
+		return nil, err
+	}
+	tx.Ownership = *own
+	return tx, nil
+}
+
+// VerifyTransfer 对发送方当前余额密文 balance 验证转账的全部证明
+func VerifyTransfer(params *Params, balance *confamount.Ciphertext, tx *Transfer) error {
+	pks := []twistededwards.PointAffine{tx.From, tx.To}
+	cts := []confamount.Ciphertext{tx.Sender, tx.Receiver}
+	if confamount.VerifyEquality(&params.Amount, pks, cts, &tx.Equality) != nil {
+		return ErrInvalidTransfer
+	}
+	commitments := []twistededwards.PointAffine{tx.Sender.Y, tx.Remaining}
+	if rangeproof.Verify(params.Range, commitments, &tx.Range) != nil {
+		return ErrInvalidTransfer
+	}
+	if !verifyOwnership(params, tx, confamount.Sub(balance, &tx.Sender)) {
+		return ErrInvalidTransfer
+	}
+	return nil
+}
+
+func proveOwnership(rand io.Reader, params *Params, tx *Transfer, newBalance *confamount.Ciphertext, s, rest, rPrime *big.Int) (*OwnershipProof, error) {
+	ks, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	kr, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// T1 = ks·P_from，T2 = ks·X + kb·h，T3 = kr·G + kb·h
+	var T1, T2, T3, ind twistededwards.PointAffine
+	T1.ScalarMultiplication(&tx.From, ks)
+	T2.ScalarMultiplication(&newBalance.X, ks)
+	ind.ScalarMultiplication(&params.Amount.H, kb)
+	T2.Add(&T2, &ind)
+	T3 = params.Amount.Commit(kb, kr)
+
+	c := ownershipChallenge(params, tx, newBalance, &T1, &T2, &T3)
+	proof := new(OwnershipProof)
+	proof.C.Set(c)
+	proof.Zs.Set(response(ks, c, s))
+	proof.Zb.Set(response(kb, c, rest))
+	proof.Zr.Set(response(kr, c, rPrime))
+	return proof, nil
+}
+
+// response 计算 k + c·w mod order
+func response(k, c, w *big.Int) *big.Int {
+	z := new(big.Int).Mul(c, w)
+	return curveutil.ModOrder(z.Add(z, k))
+}
+
+func verifyOwnership(params *Params, tx *Transfer, newBalance *confamount.Ciphertext) bool {
+	proof := &tx.Ownership
+	negC := new(big.Int).Neg(&proof.C)
+
+	// T1' = zs·P_from − c·G
+	var T1, T2, T3, ind twistededwards.PointAffine
+	T1.ScalarMultiplication(&tx.From, &proof.Zs)
+	ind.ScalarMultiplication(&params.Amount.G, negC)
+	T1.Add(&T1, &ind)
+
+	// T2' = zs·X + zb·h − c·Y
+	T2.ScalarMultiplication(&newBalance.X, &proof.Zs)
+	ind.ScalarMultiplication(&params.Amount.H, &proof.Zb)
+	T2.Add(&T2, &ind)
+	ind.ScalarMultiplication(&newBalance.Y, negC)
+	T2.Add(&T2, &ind)
+
+	// T3' = zr·G + zb·h − c·Y'
+	T3 = params.Amount.Commit(&proof.Zb, &proof.Zr)
+	ind.ScalarMultiplication(&tx.Remaining, negC)
+	T3.Add(&T3, &ind)
+
+	c := ownershipChallenge(params, tx, newBalance, &T1, &T2, &T3)
+	return c.Cmp(&proof.C) == 0
+}
+
+// ownershipChallenge 计算 H(G, h, 交易各字段, 新余额, T1, T2, T3) mod order，绑定整笔交易
+func ownershipChallenge(params *Params, tx *Transfer, newBalance *confamount.Ciphertext, T1, T2, T3 *twistededwards.PointAffine) *big.Int {
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], tx.Nonce)
+	return curveutil.HashToScalar(ownershipDomain,
+		params.Amount.G.Marshal(), params.Amount.H.Marshal(),
+		tx.From.Marshal(), tx.To.Marshal(), nonce[:],
+		tx.Sender.X.Marshal(), tx.Sender.Y.Marshal(),
+		tx.Receiver.X.Marshal(), tx.Receiver.Y.Marshal(),
+		tx.Remaining.Marshal(),
+		newBalance.X.Marshal(), newBalance.Y.Marshal(),
+		T1.Marshal(), T2.Marshal(), T3.Marshal())
+}
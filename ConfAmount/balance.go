@@ -0,0 +1,144 @@
+package confamount
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// balanceDomain 平衡证明挑战的域分隔标签
+const balanceDomain = "LYcode/ConfAmount/BalanceProof/v1"
+
+// BalanceProofSize BalanceProof 序列化后的长度
+const BalanceProofSize = 2 * curveutil.ScalarSize
+
+var (
+	ErrUnbalanced          = errors.New("confamount: inputs do not equal outputs plus fee")
+	ErrInvalidBalanceProof = errors.New("confamount: invalid balance proof")
+)
+
+// BalanceProof 证明 Σ Y_in − Σ Y_out − fee·h = Δr·G，且证明者知道 Δr = Σ r_in − Σ r_out
+type BalanceProof struct {
+	C big.Int // 挑战
+	Z big.Int // k + c·Δr
+}
+
+// BalanceExcess 计算 D = Σ Y_in − Σ Y_out − fee·h
+func BalanceExcess(params *Params, inputs, outputs []twistededwards.PointAffine, fee uint64) twistededwards.PointAffine {
+	D := curveutil.Identity()
+	var ind twistededwards.PointAffine
+	for i := range inputs {
+		D.Add(&D, &inputs[i])
+	}
+	for i := range outputs {
+		ind.Neg(&outputs[i])
+		D.Add(&D, &ind)
+	}
+	ind.ScalarMultiplication(&params.H, new(big.Int).SetUint64(fee))
+	ind.Neg(&ind)
+	D.Add(&D, &ind)
+	return D
+}
+
+// ProveBalance 由输入、输出承诺的盲化因子生成平衡证明
+func ProveBalance(rand io.Reader, params *Params, inputs, outputs []twistededwards.PointAffine, fee uint64, inBlinds, outBlinds []*big.Int) (*BalanceProof, error) {
+	if len(inBlinds) != len(inputs) || len(outBlinds) != len(outputs) {
+		return nil, ErrUnbalanced
+	}
+	delta := new(big.Int)
+	for _, r := range inBlinds {
+		delta.Add(delta, r)
+	}
+	for _, r := range outBlinds {
+		delta.Sub(delta, r)
+	}
+	delta = curveutil.ModOrder(delta)
+
+	D := BalanceExcess(params, inputs, outputs, fee)
+	var check twistededwards.PointAffine
+	check.ScalarMultiplication(&params.G, delta)
+	if !check.Equal(&D) {
+		return nil, ErrUnbalanced
+	}
+
+	k, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	var K twistededwards.PointAffine
+	K.ScalarMultiplication(&params.G, k)
+
+	c := balanceChallenge(params, inputs, outputs, fee, &D, &K)
+	proof := new(BalanceProof)
+	proof.C.Set(c)
+	proof.Z.Mul(c, delta)
+	proof.Z.Add(&proof.Z, k)
+	proof.Z.Set(curveutil.ModOrder(&proof.Z))
+	return proof, nil
+}
+
+// VerifyBalance 验证平衡证明
+func VerifyBalance(params *Params, inputs, outputs []twistededwards.PointAffine, fee uint64, proof *BalanceProof) error {
+	D := BalanceExcess(params, inputs, outputs, fee)
+
+	// K' = z·G − c·D
+	var K, ind twistededwards.PointAffine
+	K.ScalarMultiplication(&params.G, &proof.Z)
+	ind.ScalarMultiplication(&D, new(big.Int).Neg(&proof.C))
+	K.Add(&K, &ind)
+
+	c := balanceChallenge(params, inputs, outputs, fee, &D, &K)
+	if c.Cmp(&proof.C) != 0 {
+		return ErrInvalidBalanceProof
+	}
+	return nil
+}
+
+// balanceChallenge 计算 H(G, h, {Y_in}, {Y_out}, fee, D, K) mod order
+func balanceChallenge(params *Params, inputs, outputs []twistededwards.PointAffine, fee uint64, D, K *twistededwards.PointAffine) *big.Int {
+	var counts [16]byte
+	binary.BigEndian.PutUint64(counts[:8], uint64(len(inputs)))
+	binary.BigEndian.PutUint64(counts[8:], uint64(len(outputs)))
+	var feeBytes [8]byte
+	binary.BigEndian.PutUint64(feeBytes[:], fee)
+
+	data := [][]byte{params.G.Marshal(), params.H.Marshal(), counts[:]}
+	for i := range inputs {
+		data = append(data, inputs[i].Marshal())
+	}
+	for i := range outputs {
+		data = append(data, outputs[i].Marshal())
+	}
+	data = append(data, feeBytes[:], D.Marshal(), K.Marshal())
+	return curveutil.HashToScalar(balanceDomain, data...)
+}
+
+// MarshalBinary 序列化为 c || z
+func (proof *BalanceProof) MarshalBinary() ([]byte, error) {
+	c := curveutil.ScalarBytes(&proof.C)
+	z := curveutil.ScalarBytes(&proof.Z)
+	return append(c[:], z[:]...), nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *BalanceProof) UnmarshalBinary(data []byte) error {
+	if len(data) != BalanceProofSize {
+		return ErrInvalidBalanceProof
+	}
+	c, err := curveutil.ScalarFromBytes(data[:curveutil.ScalarSize])
+	if err != nil {
+		return err
+	}
+	z, err := curveutil.ScalarFromBytes(data[curveutil.ScalarSize:])
+	if err != nil {
+		return err
+	}
+	proof.C.Set(c)
+	proof.Z.Set(z)
+	return nil
+}
@@ -0,0 +1,260 @@
+package confamount
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"runtime"
+
+	"MissionYang/RangeProof"
+	"MissionYang/RecoverM2"
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+const (
+	// LimbBits 每个分块的位数
+	LimbBits = 16
+	// NumLimbs 64 位金额的分块数
+	NumLimbs = 64 / LimbBits
+	// ChunkedCiphertextSize ChunkedCiphertext 序列化后的长度
+	ChunkedCiphertextSize = NumLimbs * 2 * curveutil.PointSize
+	// limbValiditySize 分块密文有效性证明序列化后的长度：c 与每块的 z_r、z_m
+	limbValiditySize = (1 + 2*NumLimbs) * curveutil.ScalarSize
+
+	limbValidityDomain = "LYcode/ConfAmount/LimbValidity/v1"
+)
+
+var ErrInvalidChunkedProof = errors.New("confamount: invalid chunked amount proof")
+
+// ChunkedCiphertext 分块加密的金额：Limbs[i] 加密 m 的第 i 个 16 位分块（低位在前），
+// 每块解密只需在 2^16 项的表中查找
+type ChunkedCiphertext struct {
+	Limbs [NumLimbs]Ciphertext
+}
+
+// ChunkedProof 证明各分块密文都是 pk 下的有效密文、分块都在 [0, 2^16) 内，
+// 且 Σ 2^{16i}·Y_i 与金额承诺 Y 承诺同一金额
+type ChunkedProof struct {
+	Recombine BalanceProof     // Y − Σ 2^{16i}·Y_i = δ·G
+	Validity  sigmaproof.Proof // 每块 X_i = r_i·pk 且 Y_i = r_i·G + m_i·h
+	Range     rangeproof.Proof // Y_0 … Y_3 的聚合 16 位范围证明
+}
+
+// splitLimbs 将 m 拆成 16 位分块，低位在前
+func splitLimbs(m uint64) [NumLimbs]uint64 {
+	var limbs [NumLimbs]uint64
+	for i := range limbs {
+		limbs[i] = (m >> (LimbBits * i)) & (1<<LimbBits - 1)
+	}
+	return limbs
+}
+
+// limbWeight 返回 2^{16i}
+func limbWeight(i int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(LimbBits*i))
+}
+
+// EncryptChunked 在公钥 pk 下分块加密 m，每块使用独立随机数，返回各块的随机数
+func EncryptChunked(rand io.Reader, params *Params, pk *twistededwards.PointAffine, m uint64) (*ChunkedCiphertext, []*big.Int, error) {
+	ct := new(ChunkedCiphertext)
+	rs := make([]*big.Int, NumLimbs)
+	for i, limb := range splitLimbs(m) {
+		r, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		ct.Limbs[i] = *EncryptWithRandomness(params, pk, new(big.Int).SetUint64(limb), r)
+		rs[i] = r
+	}
+	return ct, rs, nil
+}
+
+// Combine 计算 Σ 2^{16i}·ct_i，即 m 在随机数 Σ 2^{16i}·r_i 下的普通密文
+func (ct *ChunkedCiphertext) Combine() *Ciphertext {
+	res := &Ciphertext{X: curveutil.Identity(), Y: curveutil.Identity()}
+	var ind twistededwards.PointAffine
+	for i := range ct.Limbs {
+		w := limbWeight(i)
+		ind.ScalarMultiplication(&ct.Limbs[i].X, w)
+		res.X.Add(&res.X, &ind)
+		ind.ScalarMultiplication(&ct.Limbs[i].Y, w)
+		res.Y.Add(&res.Y, &ind)
+	}
+	return res
+}
+
+// limbCommitments 返回各分块的承诺 Y_i
+func (ct *ChunkedCiphertext) limbCommitments() []twistededwards.PointAffine {
+	ys := make([]twistededwards.PointAffine, NumLimbs)
+	for i := range ct.Limbs {
+		ys[i] = ct.Limbs[i].Y
+	}
+	return ys
+}
+
+// NewLimbRangeParams 返回分块范围证明所用的参数（16 位、聚合 4 个）
+func NewLimbRangeParams(params *Params) (*rangeproof.Params, error) {
+	return rangeproof.NewParams(params.G, params.H, LimbBits, NumLimbs)
+}
+
+// limbValidity 以 (r_0, m_0, …, r_3, m_3) 为秘密标量声明各分块密文在 pk 下有效：
+//
+//	X_i = r_i·pk，Y_i = r_i·G + m_i·h
+//
+// 没有它，X_i 可以任意选取，监管方解密出的分块与 Y_i 中的金额无关
+func limbValidity(params *Params, pk *twistededwards.PointAffine, ct *ChunkedCiphertext) *sigmaproof.Relation {
+	r := sigmaproof.New(limbValidityDomain)
+	G, H, P := r.Point(params.G), r.Point(params.H), r.Point(*pk)
+	for i := range ct.Limbs {
+		ri, mi := r.Scalar(), r.Scalar()
+		r.Equation(r.Point(ct.Limbs[i].X), sigmaproof.Term{X: ri, P: P})
+		r.Equation(r.Point(ct.Limbs[i].Y), sigmaproof.Term{X: ri, P: G}, sigmaproof.Term{X: mi, P: H})
+	}
+	return r
+}
+
+// ProveChunked 证明公钥 pk 下的分块密文 ct 与金额承诺 Y = r·G + m·h 加密同一金额，limbRs 为各块的随机数
+func ProveChunked(rand io.Reader, params *Params, rangeParams *rangeproof.Params, pk, Y *twistededwards.PointAffine, r *big.Int, m uint64, ct *ChunkedCiphertext, limbRs []*big.Int) (*ChunkedProof, error) {
+	if len(limbRs) != NumLimbs {
+		return nil, ErrInvalidChunkedProof
+	}
+	limbs := splitLimbs(m)
+	values := make([]uint64, NumLimbs)
+	weightedRs := make([]*big.Int, NumLimbs)
+	witness := make([]*big.Int, 0, 2*NumLimbs)
+	for i := range limbs {
+		values[i] = limbs[i]
+		weightedRs[i] = new(big.Int).Mul(limbRs[i], limbWeight(i))
+		witness = append(witness, limbRs[i], new(big.Int).SetUint64(limbs[i]))
+	}
+
+	validity, err := limbValidity(params, pk, ct).Prove(rand, witness, Y.Marshal())
+	if err != nil {
+		return nil, ErrInvalidChunkedProof
+	}
+
+	// Y − Σ 2^{16i}·Y_i 只剩 G 分量：把 Y 作为输入、加权分块作为输出即为平衡证明
+	recombine, err := ProveBalance(rand, params, []twistededwards.PointAffine{*Y}, weightedLimbs(ct), 0, []*big.Int{r}, weightedRs)
+	if err != nil {
+		return nil, err
+	}
+	rp, err := rangeproof.Prove(rand, rangeParams, values, limbRs)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkedProof{Recombine: *recombine, Validity: *validity, Range: *rp}, nil
+}
+
+// VerifyChunked 验证公钥 pk 下的分块密文 ct 与金额承诺 Y 的分块证明
+func VerifyChunked(params *Params, rangeParams *rangeproof.Params, pk, Y *twistededwards.PointAffine, ct *ChunkedCiphertext, proof *ChunkedProof) error {
+	if err := VerifyBalance(params, []twistededwards.PointAffine{*Y}, weightedLimbs(ct), 0, &proof.Recombine); err != nil {
+		return ErrInvalidChunkedProof
+	}
+	if err := limbValidity(params, pk, ct).Verify(&proof.Validity, Y.Marshal()); err != nil {
+		return ErrInvalidChunkedProof
+	}
+	if err := rangeproof.Verify(rangeParams, ct.limbCommitments(), &proof.Range); err != nil {
+		return ErrInvalidChunkedProof
+	}
+	return nil
+}
+
+// weightedLimbs 返回 2^{16i}·Y_i
+func weightedLimbs(ct *ChunkedCiphertext) []twistededwards.PointAffine {
+	ys := ct.limbCommitments()
+	for i := range ys {
+		ys[i].ScalarMultiplication(&ys[i], limbWeight(i))
+	}
+	return ys
+}
+
+// NewLimbTable 预计算 j·h（0 ≤ j < 2^16）的查找表，可在多次 DecryptChunked 间共用
+func NewLimbTable(params *Params) *recoverm2.Table {
+	return recoverm2.NewTable(params.H, 1<<LimbBits, runtime.NumCPU())
+}
+
+// DecryptChunked 用私钥 sk 逐块解密并查表，再按 2^{16i} 重组金额
+func DecryptChunked(params *Params, sk *big.Int, ct *ChunkedCiphertext, table *recoverm2.Table) (uint64, error) {
+	base := table.Base()
+	if !base.Equal(&params.H) {
+		return 0, recoverm2.ErrTableBase
+	}
+	var m uint64
+	for i := range ct.Limbs {
+		hm, err := DecryptToPoint(sk, &ct.Limbs[i])
+		if err != nil {
+			return 0, err
+		}
+		limb, ok := table.Lookup(hm)
+		if !ok {
+			return 0, ErrAmountNotFound
+		}
+		m |= limb << (LimbBits * i)
+	}
+	return m, nil
+}
+
+// MarshalBinary 序列化为 ct_0 || ct_1 || ct_2 || ct_3
+func (ct *ChunkedCiphertext) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 0, ChunkedCiphertextSize)
+	for i := range ct.Limbs {
+		b, err := ct.Limbs[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复分块密文
+func (ct *ChunkedCiphertext) UnmarshalBinary(data []byte) error {
+	if len(data) != ChunkedCiphertextSize {
+		return curveutil.ErrPointEncoding
+	}
+	size := ChunkedCiphertextSize / NumLimbs
+	for i := range ct.Limbs {
+		if err := ct.Limbs[i].UnmarshalBinary(data[i*size : (i+1)*size]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalBinary 序列化为 recombine || validity || range
+func (proof *ChunkedProof) MarshalBinary() ([]byte, error) {
+	if len(proof.Validity.Z) != 2*NumLimbs {
+		return nil, ErrInvalidChunkedProof
+	}
+	res, err := proof.Recombine.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	validity, err := proof.Validity.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	rp, err := proof.Range.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, validity...)
+	return append(res, rp...), nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *ChunkedProof) UnmarshalBinary(data []byte) error {
+	if len(data) < BalanceProofSize+limbValiditySize {
+		return ErrInvalidChunkedProof
+	}
+	if err := proof.Recombine.UnmarshalBinary(data[:BalanceProofSize]); err != nil {
+		return err
+	}
+	if err := proof.Validity.UnmarshalBinary(data[BalanceProofSize : BalanceProofSize+limbValiditySize]); err != nil {
+		return err
+	}
+	return proof.Range.UnmarshalBinary(data[BalanceProofSize+limbValiditySize:])
+}
@@ -0,0 +1,66 @@
+package confamount
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// decryptionDomain 可验证解密证明挑战的域分隔标签
+const decryptionDomain = "LYcode/ConfAmount/Decryption/v1"
+
+var ErrInvalidDecryptionProof = errors.New("confamount: invalid decryption proof")
+
+// DecryptionProof 证明 m·h = Y − sk^{-1}·X 且 sk 与 pk 对应：
+// 以 s = sk^{-1} 为证据，G = s·pk 与 Y − m·h = s·X 的 DLEQ 证明
+type DecryptionProof struct {
+	curveutil.DLEQ
+}
+
+// DecryptWithProof 解密得到 m·h，并给出可公开验证的解密证明
+func DecryptWithProof(rand io.Reader, params *Params, sk *big.Int, ct *Ciphertext) (twistededwards.PointAffine, *DecryptionProof, error) {
+	hm, err := DecryptToPoint(sk, ct)
+	if err != nil {
+		return twistededwards.PointAffine{}, nil, err
+	}
+	proof, err := proveDecryption(rand, decryptionDomain, params, sk, ct, &hm)
+	if err != nil {
+		return twistededwards.PointAffine{}, nil, err
+	}
+	return hm, &DecryptionProof{*proof}, nil
+}
+
+// VerifyDecryption 不需要私钥，验证 hm 是 ct 在公钥 pk 下的正确解密
+func VerifyDecryption(params *Params, pk *twistededwards.PointAffine, ct *Ciphertext, hm *twistededwards.PointAffine, proof *DecryptionProof) error {
+	if !verifyDecryption(decryptionDomain, params, pk, ct, hm, &proof.DLEQ) {
+		return ErrInvalidDecryptionProof
+	}
+	return nil
+}
+
+// proveDecryption 生成 G = s·pk 与 Y − hm = s·X 的 DLEQ 证明，s = sk^{-1}；extra 额外绑定进挑战
+func proveDecryption(rand io.Reader, domain string, params *Params, sk *big.Int, ct *Ciphertext, hm *twistededwards.PointAffine, extra ...[]byte) (*curveutil.DLEQ, error) {
+	curve := twistededwards.GetEdwardsCurve()
+	s := new(big.Int).ModInverse(sk, &curve.Order)
+	if s == nil {
+		return nil, ErrInvalidKey
+	}
+	var pk, rG twistededwards.PointAffine
+	pk.ScalarMultiplication(&params.G, sk)
+	rG.Neg(hm)
+	rG.Add(&ct.Y, &rG)
+	data := append([][]byte{params.H.Marshal(), ct.Y.Marshal(), hm.Marshal()}, extra...)
+	return curveutil.ProveDLEQ(rand, domain, &pk, &params.G, &ct.X, &rG, s, data...)
+}
+
+func verifyDecryption(domain string, params *Params, pk *twistededwards.PointAffine, ct *Ciphertext, hm *twistededwards.PointAffine, proof *curveutil.DLEQ, extra ...[]byte) bool {
+	var rG twistededwards.PointAffine
+	rG.Neg(hm)
+	rG.Add(&ct.Y, &rG)
+	data := append([][]byte{params.H.Marshal(), ct.Y.Marshal(), hm.Marshal()}, extra...)
+	return proof.Verify(domain, pk, &params.G, &ct.X, &rG, data...)
+}
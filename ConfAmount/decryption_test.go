@@ -0,0 +1,48 @@
+package confamount
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDecryptWithProof(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 2)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm, proof, err := DecryptWithProof(rand.Reader, &params, sks[0], ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := params.Commit(big.NewInt(64), big.NewInt(0)); !hm.Equal(&want) {
+		t.Fatal("decrypted point != m·h")
+	}
+	if err := VerifyDecryption(&params, &pks[0], ct, &hm, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := params.Commit(big.NewInt(65), big.NewInt(0))
+	if err := VerifyDecryption(&params, &pks[0], ct, &wrong, proof); !errors.Is(err, ErrInvalidDecryptionProof) {
+		t.Fatalf("wrong plaintext: got %v, want ErrInvalidDecryptionProof", err)
+	}
+	if err := VerifyDecryption(&params, &pks[1], ct, &hm, proof); !errors.Is(err, ErrInvalidDecryptionProof) {
+		t.Fatalf("other key: got %v, want ErrInvalidDecryptionProof", err)
+	}
+	other, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDecryption(&params, &pks[0], other, &hm, proof); !errors.Is(err, ErrInvalidDecryptionProof) {
+		t.Fatalf("other ciphertext: got %v, want ErrInvalidDecryptionProof", err)
+	}
+	tampered := &DecryptionProof{}
+	tampered.C.Add(&proof.C, big.NewInt(1))
+	tampered.Z.Set(&proof.Z)
+	if err := VerifyDecryption(&params, &pks[0], ct, &hm, tampered); !errors.Is(err, ErrInvalidDecryptionProof) {
+		t.Fatalf("tampered proof: got %v, want ErrInvalidDecryptionProof", err)
+	}
+}
@@ -0,0 +1,132 @@
+package confamount
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// disclosureDomain 金额披露证明挑战的域分隔标签
+const disclosureDomain = "LYcode/ConfAmount/Disclosure/v1"
+
+// AmountDisclosureSize AmountDisclosure 序列化后的长度
+const AmountDisclosureSize = 8 + curveutil.DLEQSize
+
+var ErrInvalidDisclosure = errors.New("confamount: invalid amount disclosure")
+
+// AmountDisclosure 接收方向审计方公开某个密文的金额 m，并零知识地证明密文在其公钥下解密为 m·h，
+// 不泄露私钥，也不涉及其他输出
+type AmountDisclosure struct {
+	M     uint64
+	Proof curveutil.DLEQ
+}
+
+// DiscloseAmount 用私钥 sk 披露 ct 中的金额 m。context 为审计方给出的上下文（如审计编号），
+// 绑定进证明以防止被转用于其他审计；可为 nil。
+func DiscloseAmount(rand io.Reader, params *Params, sk *big.Int, ct *Ciphertext, m uint64, context []byte) (*AmountDisclosure, error) {
+	hm, err := DecryptToPoint(sk, ct)
+	if err != nil {
+		return nil, err
+	}
+	expected := params.Commit(new(big.Int).SetUint64(m), big.NewInt(0))
+	if !hm.Equal(&expected) {
+		return nil, ErrAmountNotFound
+	}
+	proof, err := proveDecryption(rand, disclosureDomain, params, sk, ct, &hm, disclosureData(m, context)...)
+	if err != nil {
+		return nil, err
+	}
+	return &AmountDisclosure{M: m, Proof: *proof}, nil
+}
+
+// VerifyDisclosure 审计方用接收方公钥 pk 验证 ct 的金额确为 d.M
+func VerifyDisclosure(params *Params, pk *twistededwards.PointAffine, ct *Ciphertext, d *AmountDisclosure, context []byte) error {
+	hm := params.Commit(new(big.Int).SetUint64(d.M), big.NewInt(0))
+	if !verifyDecryption(disclosureDomain, params, pk, ct, &hm, &d.Proof, disclosureData(d.M, context)...) {
+		return ErrInvalidDisclosure
+	}
+	return nil
+}
+
+func disclosureData(m uint64, context []byte) [][]byte {
+	var mb [8]byte
+	binary.BigEndian.PutUint64(mb[:], m)
+	return [][]byte{mb[:], context}
+}
+
+// MarshalBinary 序列化为 m（8 字节大端）|| c || z
+func (d *AmountDisclosure) MarshalBinary() ([]byte, error) {
+	proof, err := d.Proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res := binary.BigEndian.AppendUint64(make([]byte, 0, AmountDisclosureSize), d.M)
+	return append(res, proof...), nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复披露
+func (d *AmountDisclosure) UnmarshalBinary(data []byte) error {
+	if len(data) != AmountDisclosureSize {
+		return ErrInvalidDisclosure
+	}
+	if err := d.Proof.UnmarshalBinary(data[8:]); err != nil {
+		return err
+	}
+	d.M = binary.BigEndian.Uint64(data[:8])
+	return nil
+}
+
+// senderDisclosureDomain 发送方金额披露证明挑战的域分隔标签
+const senderDisclosureDomain = "LYcode/ConfAmount/SenderDisclosure/v1"
+
+// SenderDisclosure 发送方用加密随机数 r 披露金额 m：X = r·pk 且 Y − m·h = r·G 的 DLEQ 证明
+type SenderDisclosure struct {
+	M     uint64
+	Proof curveutil.DLEQ
+}
+
+// DiscloseAmountBySender 发送方用加密时的随机数 r 披露 ct 中的金额 m，context 含义同 DiscloseAmount
+func DiscloseAmountBySender(rand io.Reader, params *Params, pk *twistededwards.PointAffine, ct *Ciphertext, m uint64, r *big.Int, context []byte) (*SenderDisclosure, error) {
+	expected := EncryptWithRandomness(params, pk, new(big.Int).SetUint64(m), r)
+	if !expected.X.Equal(&ct.X) || !expected.Y.Equal(&ct.Y) {
+		return nil, ErrAmountNotFound
+	}
+	rG := senderBlind(params, ct, m)
+	proof, err := curveutil.ProveDLEQ(rand, senderDisclosureDomain, &params.G, &rG, pk, &ct.X, r, disclosureData(m, context)...)
+	if err != nil {
+		return nil, err
+	}
+	return &SenderDisclosure{M: m, Proof: *proof}, nil
+}
+
+// VerifySenderDisclosure 验证 ct 在 pk 下加密的金额确为 d.M
+func VerifySenderDisclosure(params *Params, pk *twistededwards.PointAffine, ct *Ciphertext, d *SenderDisclosure, context []byte) error {
+	rG := senderBlind(params, ct, d.M)
+	if !d.Proof.Verify(senderDisclosureDomain, &params.G, &rG, pk, &ct.X, disclosureData(d.M, context)...) {
+		return ErrInvalidDisclosure
+	}
+	return nil
+}
+
+// senderBlind 计算 Y − m·h
+func senderBlind(params *Params, ct *Ciphertext, m uint64) twistededwards.PointAffine {
+	res := params.Commit(new(big.Int).SetUint64(m), big.NewInt(0))
+	res.Neg(&res)
+	res.Add(&ct.Y, &res)
+	return res
+}
+
+// MarshalBinary 序列化为 m（8 字节大端）|| c || z
+func (d *SenderDisclosure) MarshalBinary() ([]byte, error) {
+	return (*AmountDisclosure)(d).MarshalBinary()
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复披露
+func (d *SenderDisclosure) UnmarshalBinary(data []byte) error {
+	return (*AmountDisclosure)(d).UnmarshalBinary(data)
+}
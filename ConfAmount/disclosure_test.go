@@ -0,0 +1,109 @@
+package confamount
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDiscloseAmount(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 2)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(250))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit := []byte("audit 7")
+	d, err := DiscloseAmount(rand.Reader, &params, sks[0], ct, 250, audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDisclosure(&params, &pks[0], ct, d, audit); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DiscloseAmount(rand.Reader, &params, sks[0], ct, 251, audit); !errors.Is(err, ErrAmountNotFound) {
+		t.Fatalf("wrong amount: got %v, want ErrAmountNotFound", err)
+	}
+
+	// 改动金额、换审计上下文、换公钥或换密文都不能通过
+	lie := *d
+	lie.M++
+	if err := VerifyDisclosure(&params, &pks[0], ct, &lie, audit); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("changed amount: got %v, want ErrInvalidDisclosure", err)
+	}
+	if err := VerifyDisclosure(&params, &pks[0], ct, d, []byte("audit 8")); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("other context: got %v, want ErrInvalidDisclosure", err)
+	}
+	if err := VerifyDisclosure(&params, &pks[1], ct, d, audit); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("other key: got %v, want ErrInvalidDisclosure", err)
+	}
+	other, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(250))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDisclosure(&params, &pks[0], other, d, audit); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("other ciphertext: got %v, want ErrInvalidDisclosure", err)
+	}
+}
+
+func TestDisclosureMarshal(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 1)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DiscloseAmount(rand.Reader, &params, sks[0], ct, 9, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded AmountDisclosure
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDisclosure(&params, &pks[0], ct, &decoded, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][]byte{data[:len(data)-1], append(append([]byte(nil), data...), 0)} {
+		if err := decoded.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidDisclosure) {
+			t.Fatalf("%d bytes: got %v, want ErrInvalidDisclosure", len(bad), err)
+		}
+	}
+}
+
+func TestDiscloseAmountBySender(t *testing.T) {
+	params := DefaultParams()
+	_, pks := newTestKeys(t, &params, 2)
+	ct, r, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(75))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DiscloseAmountBySender(rand.Reader, &params, &pks[0], ct, 75, r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySenderDisclosure(&params, &pks[0], ct, d, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DiscloseAmountBySender(rand.Reader, &params, &pks[0], ct, 75, new(big.Int).Add(r, big.NewInt(1)), nil); !errors.Is(err, ErrAmountNotFound) {
+		t.Fatalf("wrong randomness: got %v, want ErrAmountNotFound", err)
+	}
+	lie := *d
+	lie.M--
+	if err := VerifySenderDisclosure(&params, &pks[0], ct, &lie, nil); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("changed amount: got %v, want ErrInvalidDisclosure", err)
+	}
+	if err := VerifySenderDisclosure(&params, &pks[1], ct, d, nil); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("other key: got %v, want ErrInvalidDisclosure", err)
+	}
+	if err := VerifySenderDisclosure(&params, &pks[0], ct, d, []byte("payment 2")); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Fatalf("other context: got %v, want ErrInvalidDisclosure", err)
+	}
+}
@@ -0,0 +1,186 @@
+// Package confamount 实现交易金额的 twisted ElGamal 加密：X = r·P，Y = r·G + m·h。
+package confamount
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// hDomain 默认金额生成元 h 的哈希域
+const hDomain = "LYcode/ConfAmount/h"
+
+var (
+	ErrAmountNotFound = errors.New("confamount: amount not found in range")
+	ErrInvalidKey     = errors.New("confamount: invalid secret key")
+)
+
+// Params 公共参数：随机数基点 G 与金额基点 h
+type Params struct {
+	G twistededwards.PointAffine
+	H twistededwards.PointAffine
+}
+
+// DefaultParams 返回 G 为曲线基点、h 由哈希到曲线生成（离散对数未知）的参数
+func DefaultParams() Params {
+	curve := twistededwards.GetEdwardsCurve()
+	return Params{
+		G: curve.Base,
+		H: curveutil.HashToPoint(hDomain),
+	}
+}
+
+// Ciphertext twisted ElGamal 密文
+type Ciphertext struct {
+	X twistededwards.PointAffine // r·P
+	Y twistededwards.PointAffine // r·G + m·h
+}
+
+// Commit 计算金额承诺 r·G + m·h，即密文的 Y 分量
+func (params *Params) Commit(m, r *big.Int) twistededwards.PointAffine {
+	var Y, ind twistededwards.PointAffine
+	Y.ScalarMultiplication(&params.G, r)
+	ind.ScalarMultiplication(&params.H, m)
+	Y.Add(&Y, &ind)
+	return Y
+}
+
+// EncryptWithRandomness 用给定随机数 r 将金额 m 加密给公钥 pk
+func EncryptWithRandomness(params *Params, pk *twistededwards.PointAffine, m, r *big.Int) *Ciphertext {
+	ct := new(Ciphertext)
+	ct.X.ScalarMultiplication(pk, r)
+	ct.Y = params.Commit(m, r)
+	return ct
+}
+
+// Encrypt 将金额 m 加密给公钥 pk，返回密文及所用随机数
+func Encrypt(rand io.Reader, params *Params, pk *twistededwards.PointAffine, m *big.Int) (*Ciphertext, *big.Int, error) {
+	r, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return EncryptWithRandomness(params, pk, m, r), r, nil
+}
+
+// EncryptShared 用同一随机数将金额 m 加密给多个公钥（如接收方与监管方），各密文 Y 分量相同
+func EncryptShared(rand io.Reader, params *Params, pks []twistededwards.PointAffine, m *big.Int) ([]Ciphertext, *big.Int, error) {
+	r, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	cts := make([]Ciphertext, len(pks))
+	for i := range pks {
+		cts[i] = *EncryptWithRandomness(params, &pks[i], m, r)
+	}
+	return cts, r, nil
+}
+
+// EncryptEach 用独立随机数将金额 m 分别加密给多个公钥
+func EncryptEach(rand io.Reader, params *Params, pks []twistededwards.PointAffine, m *big.Int) ([]Ciphertext, []*big.Int, error) {
+	cts := make([]Ciphertext, len(pks))
+	rs := make([]*big.Int, len(pks))
+	for i := range pks {
+		ct, r, err := Encrypt(rand, params, &pks[i], m)
+		if err != nil {
+			return nil, nil, err
+		}
+		cts[i], rs[i] = *ct, r
+	}
+	return cts, rs, nil
+}
+
+// DecryptToPoint 用私钥 sk 解密得到 m·h = Y − sk^{-1}·X
+func DecryptToPoint(sk *big.Int, ct *Ciphertext) (twistededwards.PointAffine, error) {
+	curve := twistededwards.GetEdwardsCurve()
+	var skInv big.Int
+	if skInv.ModInverse(sk, &curve.Order) == nil {
+		return twistededwards.PointAffine{}, ErrInvalidKey
+	}
+	var hm twistededwards.PointAffine
+	hm.ScalarMultiplication(&ct.X, skInv.Neg(&skInv))
+	hm.Add(&hm, &ct.Y)
+	return hm, nil
+}
+
+// Decrypt 解密并在 [0, maxAmount] 内恢复金额 m
+func Decrypt(params *Params, sk *big.Int, ct *Ciphertext, maxAmount uint64) (uint64, error) {
+	hm, err := DecryptToPoint(sk, ct)
+	if err != nil {
+		return 0, err
+	}
+	return RecoverAmount(params, &hm, maxAmount)
+}
+
+// RecoverAmount 在 [0, maxAmount] 内求 m 使 m·h = hm，逐次加 h 搜索
+func RecoverAmount(params *Params, hm *twistededwards.PointAffine, maxAmount uint64) (uint64, error) {
+	var candidate twistededwards.PointAffine
+	candidate.X.SetZero()
+	candidate.Y.SetOne()
+	for m := uint64(0); ; m++ {
+		if candidate.Equal(hm) {
+			return m, nil
+		}
+		if m == maxAmount {
+			return 0, ErrAmountNotFound
+		}
+		candidate.Add(&candidate, &params.H)
+	}
+}
+
+// ReRandomize 用新随机数刷新密文，明文不变
+func ReRandomize(rand io.Reader, params *Params, pk *twistededwards.PointAffine, ct *Ciphertext) (*Ciphertext, *big.Int, error) {
+	r, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	zero := EncryptWithRandomness(params, pk, big.NewInt(0), r)
+	return Add(ct, zero), r, nil
+}
+
+// Add 同一公钥下密文同态相加，结果加密 m1 + m2
+func Add(ct1, ct2 *Ciphertext) *Ciphertext {
+	res := new(Ciphertext)
+	res.X.Add(&ct1.X, &ct2.X)
+	res.Y.Add(&ct1.Y, &ct2.Y)
+	return res
+}
+
+// Sub 同一公钥下密文同态相减，结果加密 m1 − m2
+func Sub(ct1, ct2 *Ciphertext) *Ciphertext {
+	var negX, negY twistededwards.PointAffine
+	negX.Neg(&ct2.X)
+	negY.Neg(&ct2.Y)
+	res := new(Ciphertext)
+	res.X.Add(&ct1.X, &negX)
+	res.Y.Add(&ct1.Y, &negY)
+	return res
+}
+
+// MarshalBinary 序列化为 X || Y（压缩点）
+func (ct *Ciphertext) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 0, 2*curveutil.PointSize)
+	res = append(res, ct.X.Marshal()...)
+	res = append(res, ct.Y.Marshal()...)
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复密文
+func (ct *Ciphertext) UnmarshalBinary(data []byte) error {
+	if len(data) != 2*curveutil.PointSize {
+		return curveutil.ErrPointEncoding
+	}
+	X, err := curveutil.PointFromBytes(data[:curveutil.PointSize])
+	if err != nil {
+		return err
+	}
+	Y, err := curveutil.PointFromBytes(data[curveutil.PointSize:])
+	if err != nil {
+		return err
+	}
+	ct.X, ct.Y = X, Y
+	return nil
+}
@@ -0,0 +1,184 @@
+package confamount
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// newTestKeys 生成 n 个接收方密钥对
+func newTestKeys(t *testing.T, params *Params, n int) ([]*big.Int, []twistededwards.PointAffine) {
+	t.Helper()
+	sks := make([]*big.Int, n)
+	pks := make([]twistededwards.PointAffine, n)
+	for i := range sks {
+		sk, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sks[i] = sk
+		pks[i].ScalarMultiplication(&params.G, sk)
+	}
+	return sks, pks
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 4)
+	amounts := []int64{0, 1, 17, 1000}
+
+	for i := range pks {
+		m := big.NewInt(amounts[i])
+		ct, _, err := Encrypt(rand.Reader, &params, &pks[i], m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hm, err := DecryptToPoint(sks[i], ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want twistededwards.PointAffine
+		want.ScalarMultiplication(&params.H, m)
+		if !hm.Equal(&want) {
+			t.Fatalf("recipient %d: DecryptToPoint != m·h", i)
+		}
+		got, err := Decrypt(&params, sks[i], ct, 1000)
+		if err != nil || got != uint64(amounts[i]) {
+			t.Fatalf("recipient %d: got (%d, %v), want %d", i, got, err, amounts[i])
+		}
+		// 别的私钥解不出同一金额
+		if other, err := Decrypt(&params, sks[(i+1)%len(sks)], ct, 1000); err == nil && other == uint64(amounts[i]) {
+			t.Fatalf("recipient %d: decrypted with the wrong key", i)
+		}
+	}
+
+	// 共用随机数与独立随机数的多接收方加密，每个接收方都能解出同一金额
+	m := big.NewInt(42)
+	shared, _, err := EncryptShared(rand.Reader, &params, pks, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	each, _, err := EncryptEach(rand.Reader, &params, pks, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range pks {
+		if !shared[i].Y.Equal(&shared[0].Y) {
+			t.Fatalf("recipient %d: shared ciphertexts have different Y", i)
+		}
+		for _, ct := range []*Ciphertext{&shared[i], &each[i]} {
+			if got, err := Decrypt(&params, sks[i], ct, 100); err != nil || got != 42 {
+				t.Fatalf("recipient %d: got (%d, %v), want 42", i, got, err)
+			}
+		}
+	}
+}
+
+func TestReRandomize(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 1)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(55))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, _, err := ReRandomize(rand.Reader, &params, &pks[0], ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.X.Equal(&ct.X) || fresh.Y.Equal(&ct.Y) {
+		t.Fatal("ciphertext unchanged")
+	}
+	if got, err := Decrypt(&params, sks[0], fresh, 100); err != nil || got != 55 {
+		t.Fatalf("got (%d, %v), want 55", got, err)
+	}
+}
+
+func TestHomomorphic(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 1)
+	ct1, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct2, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Decrypt(&params, sks[0], Add(ct1, ct2), 100); err != nil || got != 42 {
+		t.Fatalf("Add: got (%d, %v), want 42", got, err)
+	}
+	if got, err := Decrypt(&params, sks[0], Sub(ct1, ct2), 100); err != nil || got != 18 {
+		t.Fatalf("Sub: got (%d, %v), want 18", got, err)
+	}
+	// 差为负数时落在 [0, maxAmount] 之外
+	if _, err := Decrypt(&params, sks[0], Sub(ct2, ct1), 100); !errors.Is(err, ErrAmountNotFound) {
+		t.Fatalf("negative Sub: got %v, want ErrAmountNotFound", err)
+	}
+}
+
+func TestDecryptBound(t *testing.T) {
+	params := DefaultParams()
+	sks, pks := newTestKeys(t, &params, 1)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Decrypt(&params, sks[0], ct, 100); err != nil || got != 100 {
+		t.Fatalf("at bound: got (%d, %v), want 100", got, err)
+	}
+	if _, err := Decrypt(&params, sks[0], ct, 99); !errors.Is(err, ErrAmountNotFound) {
+		t.Fatalf("above bound: got %v, want ErrAmountNotFound", err)
+	}
+	if _, err := Decrypt(&params, big.NewInt(0), ct, 100); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("zero key: got %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestCiphertextMarshal(t *testing.T) {
+	params := DefaultParams()
+	_, pks := newTestKeys(t, &params, 1)
+	ct, _, err := Encrypt(rand.Reader, &params, &pks[0], big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ct.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Ciphertext
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.X.Equal(&ct.X) || !decoded.Y.Equal(&ct.Y) {
+		t.Fatal("round trip changed the ciphertext")
+	}
+	if err := decoded.UnmarshalBinary(data[1:]); err == nil {
+		t.Fatal("truncated ciphertext accepted")
+	}
+}
+
+// EncryptWithRandomness 与 main.go 第 10 步手工计算的 X = r·P，Y = r·G + m·h 一致
+func TestMatchesExistingFlow(t *testing.T) {
+	params := DefaultParams()
+	_, pks := newTestKeys(t, &params, 1)
+	r, err := curveutil.RandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(17)
+	var X, Y, mh twistededwards.PointAffine
+	X.ScalarMultiplication(&pks[0], r)
+	Y.ScalarMultiplication(&params.G, r)
+	mh.ScalarMultiplication(&params.H, m)
+	Y.Add(&Y, &mh)
+
+	ct := EncryptWithRandomness(&params, &pks[0], m, r)
+	if !ct.X.Equal(&X) || !ct.Y.Equal(&Y) {
+		t.Fatal("ciphertext differs from X = r·P, Y = r·G + m·h")
+	}
+}
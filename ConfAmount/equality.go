@@ -0,0 +1,194 @@
+package confamount
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// equalityDomain 明文相等证明挑战的域分隔标签
+const equalityDomain = "LYcode/ConfAmount/EqualityProof/v1"
+
+var ErrInvalidEqualityProof = errors.New("confamount: invalid plaintext-equality proof")
+
+// EqualityProof 证明 k 个不同公钥下的密文加密同一金额 m。
+// 共享随机数模式下所有密文使用同一 r（Y 分量相同），只有一个随机数响应；
+// 独立随机数模式下每个密文各有 r_i 与响应 z_i。
+type EqualityProof struct {
+	Shared bool
+	C      big.Int   // 挑战
+	Z      []big.Int // a_i + c·r_i
+	Zm     big.Int   // b + c·m
+}
+
+// ProveEquality 证明 cts[i] = (r_i·pks[i], r_i·G + m·h) 对所有 i 成立。
+// len(rs) == 1 时为共享随机数模式，否则要求 len(rs) == len(cts)。
+func ProveEquality(rand io.Reader, params *Params, pks []twistededwards.PointAffine, cts []Ciphertext, m *big.Int, rs []*big.Int) (*EqualityProof, error) {
+	k := len(cts)
+	if k == 0 || len(pks) != k || (len(rs) != 1 && len(rs) != k) {
+		return nil, ErrInvalidEqualityProof
+	}
+	shared := len(rs) == 1
+	for i := range cts {
+		ct := EncryptWithRandomness(params, &pks[i], m, rs[randIndex(shared, i)])
+		if !ct.X.Equal(&cts[i].X) || !ct.Y.Equal(&cts[i].Y) {
+			return nil, ErrInvalidEqualityProof
+		}
+	}
+
+	a := make([]*big.Int, len(rs))
+	for i := range a {
+		var err error
+		if a[i], err = curveutil.RandomScalar(rand); err != nil {
+			return nil, err
+		}
+	}
+	b, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// A_i = a_i·P_i，B_i = a_i·G + b·h
+	A := make([]twistededwards.PointAffine, k)
+	B := make([]twistededwards.PointAffine, len(rs))
+	for i := range cts {
+		A[i].ScalarMultiplication(&pks[i], a[randIndex(shared, i)])
+	}
+	for i := range B {
+		B[i] = params.Commit(b, a[i])
+	}
+
+	c := equalityChallenge(params, shared, pks, cts, A, B)
+
+	proof := &EqualityProof{Shared: shared, Z: make([]big.Int, len(rs))}
+	proof.C.Set(c)
+	for i := range rs {
+		proof.Z[i].Mul(c, rs[i])
+		proof.Z[i].Add(&proof.Z[i], a[i])
+		proof.Z[i].Set(curveutil.ModOrder(&proof.Z[i]))
+	}
+	proof.Zm.Mul(c, m)
+	proof.Zm.Add(&proof.Zm, b)
+	proof.Zm.Set(curveutil.ModOrder(&proof.Zm))
+	return proof, nil
+}
+
+// VerifyEquality 验证明文相等证明
+func VerifyEquality(params *Params, pks []twistededwards.PointAffine, cts []Ciphertext, proof *EqualityProof) error {
+	k := len(cts)
+	if k == 0 || len(pks) != k {
+		return ErrInvalidEqualityProof
+	}
+	if (proof.Shared && len(proof.Z) != 1) || (!proof.Shared && len(proof.Z) != k) {
+		return ErrInvalidEqualityProof
+	}
+	// 共享随机数时所有 Y 必须相同
+	if proof.Shared {
+		for i := 1; i < k; i++ {
+			if !cts[i].Y.Equal(&cts[0].Y) {
+				return ErrInvalidEqualityProof
+			}
+		}
+	}
+
+	negC := new(big.Int).Neg(&proof.C)
+	var ind twistededwards.PointAffine
+
+	// A_i = z_i·P_i − c·X_i
+	A := make([]twistededwards.PointAffine, k)
+	for i := range cts {
+		A[i].ScalarMultiplication(&pks[i], &proof.Z[randIndex(proof.Shared, i)])
+		ind.ScalarMultiplication(&cts[i].X, negC)
+		A[i].Add(&A[i], &ind)
+	}
+	// B_i = z_i·G + zm·h − c·Y_i
+	B := make([]twistededwards.PointAffine, len(proof.Z))
+	for i := range B {
+		B[i] = params.Commit(&proof.Zm, &proof.Z[i])
+		ind.ScalarMultiplication(&cts[i].Y, negC)
+		B[i].Add(&B[i], &ind)
+	}
+
+	c := equalityChallenge(params, proof.Shared, pks, cts, A, B)
+	if c.Cmp(&proof.C) != 0 {
+		return ErrInvalidEqualityProof
+	}
+	return nil
+}
+
+// randIndex 返回第 i 个密文所用随机数的下标
+func randIndex(shared bool, i int) int {
+	if shared {
+		return 0
+	}
+	return i
+}
+
+// equalityChallenge 计算 H(G, h, mode, {P_i, X_i, Y_i}, {A_i}, {B_i}) mod order
+func equalityChallenge(params *Params, shared bool, pks []twistededwards.PointAffine, cts []Ciphertext, A, B []twistededwards.PointAffine) *big.Int {
+	mode := []byte{0}
+	if shared {
+		mode[0] = 1
+	}
+	data := [][]byte{params.G.Marshal(), params.H.Marshal(), mode}
+	for i := range cts {
+		data = append(data, pks[i].Marshal(), cts[i].X.Marshal(), cts[i].Y.Marshal())
+	}
+	for i := range A {
+		data = append(data, A[i].Marshal())
+	}
+	for i := range B {
+		data = append(data, B[i].Marshal())
+	}
+	return curveutil.HashToScalar(equalityDomain, data...)
+}
+
+// MarshalBinary 序列化为 mode || len(Z) || c || zm || z_1 ... z_n
+func (proof *EqualityProof) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 5, 5+(2+len(proof.Z))*curveutil.ScalarSize)
+	if proof.Shared {
+		res[0] = 1
+	}
+	binary.BigEndian.PutUint32(res[1:5], uint32(len(proof.Z)))
+	for _, k := range append([]*big.Int{&proof.C, &proof.Zm}, bigPtrs(proof.Z)...) {
+		b := curveutil.ScalarBytes(k)
+		res = append(res, b[:]...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *EqualityProof) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 || data[0] > 1 {
+		return ErrInvalidEqualityProof
+	}
+	n := int(binary.BigEndian.Uint32(data[1:5]))
+	if n == 0 || len(data) != 5+(2+n)*curveutil.ScalarSize {
+		return ErrInvalidEqualityProof
+	}
+	proof.Shared = data[0] == 1
+	proof.Z = make([]big.Int, n)
+	for i, k := range append([]*big.Int{&proof.C, &proof.Zm}, bigPtrs(proof.Z)...) {
+		off := 5 + i*curveutil.ScalarSize
+		v, err := curveutil.ScalarFromBytes(data[off : off+curveutil.ScalarSize])
+		if err != nil {
+			return err
+		}
+		k.Set(v)
+	}
+	return nil
+}
+
+// bigPtrs 返回切片中各元素的指针
+func bigPtrs(v []big.Int) []*big.Int {
+	res := make([]*big.Int, len(v))
+	for i := range v {
+		res[i] = &v[i]
+	}
+	return res
+}
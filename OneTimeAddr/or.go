@@ -0,0 +1,96 @@
+package onetimeaddr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/SigmaProof"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+const (
+	// keyDomain 一次性私钥知识证明的域分隔标签
+	keyDomain = "LYcode/OneTimeAddr/Key/v1"
+	// keyOrDomain、addrOrDomain 析取证明挑战的域分隔标签
+	keyOrDomain  = "LYcode/OneTimeAddr/KeyOr/v1"
+	addrOrDomain = "LYcode/OneTimeAddr/AddrOr/v1"
+)
+
+// KeyOrProof 证明知道 otas 中某一个一次性地址的私钥 x（ota = x·G），不泄露是哪一个
+type KeyOrProof struct {
+	sigmaproof.OrProof
+}
+
+// AddrOrProof 证明 statements 中某一个陈述的 ZkAddrProof 关系成立，不泄露是哪一个
+type AddrOrProof struct {
+	sigmaproof.OrProof
+}
+
+// ProveKeyOr 用 otas[index] 的一次性私钥 x 生成 1-of-k 私钥知识证明，message 绑定进挑战
+func ProveKeyOr(rand io.Reader, params *Params, otas []twistededwards.PointAffine, index int, x *big.Int, message []byte) (*KeyOrProof, error) {
+	proof, err := keyOr(params, otas).Prove(rand, index, []*big.Int{x}, message)
+	if errors.Is(err, sigmaproof.ErrInvalidWitness) {
+		return nil, ErrInvalidWitness
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &KeyOrProof{*proof}, nil
+}
+
+// VerifyKeyOr 验证 1-of-k 私钥知识证明
+func VerifyKeyOr(params *Params, otas []twistededwards.PointAffine, proof *KeyOrProof, message []byte) error {
+	if keyOr(params, otas).Verify(&proof.OrProof, message) != nil {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// ProveAddrOr 用 statements[index] 的证据生成 1-of-k ZkAddrProof 析取证明
+func ProveAddrOr(rand io.Reader, params *Params, statements []Statement, index int, witness *Witness) (*AddrOrProof, error) {
+	if witness.U == nil || witness.T == nil {
+		return nil, ErrInvalidWitness
+	}
+	proof, err := addrOr(params, statements).Prove(rand, index, []*big.Int{witness.U, witness.T})
+	if errors.Is(err, sigmaproof.ErrInvalidWitness) {
+		return nil, ErrInvalidWitness
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AddrOrProof{*proof}, nil
+}
+
+// VerifyAddrOr 验证 1-of-k ZkAddrProof 析取证明
+func VerifyAddrOr(params *Params, statements []Statement, proof *AddrOrProof) error {
+	if addrOr(params, statements).Verify(&proof.OrProof) != nil {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// KeyRelation 以 x 为秘密标量声明 ota = x·G
+func KeyRelation(params *Params, ota *twistededwards.PointAffine) *sigmaproof.Relation {
+	r := sigmaproof.New(keyDomain)
+	G, P := r.Point(params.G), r.Point(*ota)
+	r.Equation(P, sigmaproof.Term{X: r.Scalar(), P: G})
+	return r
+}
+
+func keyOr(params *Params, otas []twistededwards.PointAffine) *sigmaproof.Or {
+	rels := make([]*sigmaproof.Relation, len(otas))
+	for i := range otas {
+		rels[i] = KeyRelation(params, &otas[i])
+	}
+	return sigmaproof.NewOr(keyOrDomain, rels...)
+}
+
+func addrOr(params *Params, statements []Statement) *sigmaproof.Or {
+	rels := make([]*sigmaproof.Relation, len(statements))
+	for i := range statements {
+		rels[i] = AddrRelation(params, &statements[i])
+	}
+	return sigmaproof.NewOr(addrOrDomain, rels...)
+}
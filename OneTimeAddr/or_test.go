@@ -0,0 +1,139 @@
+package onetimeaddr
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/SigmaProof"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 回卷 KeyRelation 的证明者必须提取出 ota 的私钥
+func TestKeyRelationExtract(t *testing.T) {
+	a := newTestAddr(t)
+	p, err := KeyRelation(a.params, &a.witness.Ota).NewProver(rand.Reader, []*big.Int{a.otaSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := sigmaproof.Rewind(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Cmp(a.otaSecret) != 0 {
+		t.Fatalf("extracted %v, want %v", got[0], a.otaSecret)
+	}
+}
+
+// testOtas 返回 n 个一次性地址，第 index 个为 a 的 ota
+func testOtas(t *testing.T, a *testAddr, n, index int) []twistededwards.PointAffine {
+	t.Helper()
+	otas := make([]twistededwards.PointAffine, n)
+	for i := range otas {
+		otas[i].ScalarMultiplication(&a.params.G, mustScalar(t))
+	}
+	otas[index] = a.witness.Ota
+	return otas
+}
+
+func TestKeyOr(t *testing.T) {
+	a := newTestAddr(t)
+	otas := testOtas(t, a, 4, 2)
+	msg := []byte("spend 1")
+	proof, err := ProveKeyOr(rand.Reader, a.params, otas, 2, a.otaSecret, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyKeyOr(a.params, otas, proof, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded KeyOrProof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyKeyOr(a.params, otas, &decoded, msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Fatal("truncated proof accepted")
+	}
+
+	for _, index := range []int{1, -1, len(otas)} {
+		if _, err := ProveKeyOr(rand.Reader, a.params, otas, index, a.otaSecret, msg); !errors.Is(err, ErrInvalidWitness) {
+			t.Fatalf("index %d: got %v, want ErrInvalidWitness", index, err)
+		}
+	}
+}
+
+func TestKeyOrRejected(t *testing.T) {
+	a := newTestAddr(t)
+	otas := testOtas(t, a, 4, 2)
+	msg := []byte("spend 1")
+	proof, err := ProveKeyOr(rand.Reader, a.params, otas, 2, a.otaSecret, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyKeyOr(a.params, otas, proof, []byte("spend 2")); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("other message: got %v, want ErrInvalidProof", err)
+	}
+	replaced := append([]twistededwards.PointAffine(nil), otas...)
+	replaced[0].Add(&replaced[0], &a.params.G)
+	if err := VerifyKeyOr(a.params, replaced, proof, msg); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("replaced ota: got %v, want ErrInvalidProof", err)
+	}
+	if err := VerifyKeyOr(a.params, otas[:3], proof, msg); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("shorter ring: got %v, want ErrInvalidProof", err)
+	}
+
+	// 交换两个分支的挑战，总和不变但分支不再成立
+	swapped := &KeyOrProof{}
+	swapped.C = append([]big.Int(nil), proof.C...)
+	swapped.Z = proof.Z
+	swapped.C[0], swapped.C[1] = proof.C[1], proof.C[0]
+	if err := VerifyKeyOr(a.params, otas, swapped, msg); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("swapped challenges: got %v, want ErrInvalidProof", err)
+	}
+}
+
+func TestAddrOr(t *testing.T) {
+	a := newTestAddr(t)
+	other := newTestAddr(t)
+	statements := []Statement{other.witness.Statement, a.witness.Statement}
+	proof, err := ProveAddrOr(rand.Reader, a.params, statements, 1, a.witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAddrOr(a.params, statements, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ProveAddrOr(rand.Reader, a.params, statements, 0, a.witness); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("wrong index: got %v, want ErrInvalidWitness", err)
+	}
+	if _, err := ProveAddrOr(rand.Reader, a.params, statements, 1, &Witness{Statement: a.witness.Statement}); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("missing witness: got %v, want ErrInvalidWitness", err)
+	}
+
+	// 换掉陈述中的任一点或换一个监管公钥都不能通过
+	moved := append([]Statement(nil), statements...)
+	moved[1].C2 = other.witness.C2
+	if err := VerifyAddrOr(a.params, moved, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("other C2: got %v, want ErrInvalidProof", err)
+	}
+	moved = append([]Statement(nil), statements...)
+	moved[0].Ota = a.witness.Ota
+	if err := VerifyAddrOr(a.params, moved, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("other decoy: got %v, want ErrInvalidProof", err)
+	}
+	if err := VerifyAddrOr(other.params, statements, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("other pk_rev: got %v, want ErrInvalidProof", err)
+	}
+}
@@ -0,0 +1,166 @@
+package onetimeaddr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// paymentDomain 支付证明挑战的域分隔标签
+const paymentDomain = "LYcode/OneTimeAddr/Payment/v1"
+
+// PaymentProofSize 不含金额披露时 PaymentProof 序列化后的长度
+const PaymentProofSize = curveutil.PointSize + curveutil.DLEQSize + 1
+
+var ErrInvalidPayment = errors.New("onetimeaddr: invalid payment proof")
+
+// PaymentProof 发送方用交易私钥 r_t 证明向 pk_r 付款：公开共享点 K = r_t·pk_r，
+// 以 DLEQ 证明 Rt = r_t·G 与 K = r_t·pk_r 使用同一 r_t，验证方据此检查 ota = H(K)·G + pk_r。
+// 可选地附带发送方对金额密文的披露。
+type PaymentProof struct {
+	Shared twistededwards.PointAffine
+	Proof  curveutil.DLEQ
+	Amount *confamount.SenderDisclosure // nil 表示不披露金额
+}
+
+// AmountOutput 与一次性地址同属一个输出的金额密文 Ct 及其加密公钥 PK
+type AmountOutput struct {
+	Params *confamount.Params
+	PK     twistededwards.PointAffine
+	Ct     confamount.Ciphertext
+}
+
+// PaymentAmount 发送方披露金额所需的数据：Ct 以随机数 R 加密金额 M
+type PaymentAmount struct {
+	AmountOutput
+	M uint64
+	R *big.Int
+}
+
+// ProvePayment 由交易私钥 rt 生成向 pk_r 付款的证明，amount 为 nil 时不披露金额。
+// context 为仲裁方给出的上下文，绑定进证明；可为 nil。
+func ProvePayment(rand io.Reader, params *Params, rt *big.Int, pkR, ota *twistededwards.PointAffine, amount *PaymentAmount, context []byte) (*PaymentProof, error) {
+	var Rt twistededwards.PointAffine
+	Rt.ScalarMultiplication(&params.G, rt)
+	proof := new(PaymentProof)
+	proof.Shared.ScalarMultiplication(pkR, rt)
+	if !derivesOta(params, &proof.Shared, pkR, ota) {
+		return nil, ErrInvalidWitness
+	}
+
+	data := paymentData(&Rt, pkR, ota, context)
+	dleq, err := curveutil.ProveDLEQ(rand, paymentDomain, &params.G, &Rt, pkR, &proof.Shared, rt, data...)
+	if err != nil {
+		return nil, err
+	}
+	proof.Proof = *dleq
+
+	if amount != nil {
+		d, err := confamount.DiscloseAmountBySender(rand, amount.Params, &amount.PK, &amount.Ct, amount.M, amount.R, paymentContext(data))
+		if err != nil {
+			return nil, err
+		}
+		proof.Amount = d
+	}
+	return proof, nil
+}
+
+// VerifyPayment 用链上公开的 Rt、ota 验证付款证明。amount 与 proof.Amount 须同时给出或同时为 nil：
+// 前者时通过后 proof.Amount.M 即为付款金额，只有一方为 nil 时返回 ErrInvalidPayment。
+func VerifyPayment(params *Params, Rt, pkR, ota *twistededwards.PointAffine, amount *AmountOutput, proof *PaymentProof, context []byte) error {
+	if !derivesOta(params, &proof.Shared, pkR, ota) {
+		return ErrInvalidPayment
+	}
+	data := paymentData(Rt, pkR, ota, context)
+	if !proof.Proof.Verify(paymentDomain, &params.G, Rt, pkR, &proof.Shared, data...) {
+		return ErrInvalidPayment
+	}
+	if (proof.Amount == nil) != (amount == nil) {
+		return ErrInvalidPayment
+	}
+	if proof.Amount != nil {
+		if confamount.VerifySenderDisclosure(amount.Params, &amount.PK, &amount.Ct, proof.Amount, paymentContext(data)) != nil {
+			return ErrInvalidPayment
+		}
+	}
+	return nil
+}
+
+// derivesOta 检查 ota = H(K)·G + pk_r，H 与一次性地址生成时一致
+func derivesOta(params *Params, shared, pkR, ota *twistededwards.PointAffine) bool {
+	digest := sha256.Sum256(shared.Marshal())
+	var res twistededwards.PointAffine
+	res.ScalarMultiplication(&params.G, new(big.Int).SetBytes(digest[:]))
+	res.Add(&res, pkR)
+	return res.Equal(ota)
+}
+
+func paymentData(Rt, pkR, ota *twistededwards.PointAffine, context []byte) [][]byte {
+	return [][]byte{Rt.Marshal(), pkR.Marshal(), ota.Marshal(), context}
+}
+
+// paymentContext 金额披露的上下文，使披露只对这一付款证明有效
+func paymentContext(data [][]byte) []byte {
+	var res []byte
+	for _, b := range data {
+		res = append(res, b...)
+	}
+	return res
+}
+
+// MarshalBinary 序列化为 K || c || z || flag，flag 为 1 时其后为金额披露
+func (proof *PaymentProof) MarshalBinary() ([]byte, error) {
+	shared := proof.Shared.Bytes()
+	dleq, err := proof.Proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res := append(shared[:], dleq...)
+	if proof.Amount == nil {
+		return append(res, 0), nil
+	}
+	d, err := proof.Amount.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, 1)
+	return append(res, d...), nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *PaymentProof) UnmarshalBinary(data []byte) error {
+	if len(data) < PaymentProofSize {
+		return ErrInvalidPayment
+	}
+	shared, err := curveutil.PointFromBytes(data[:curveutil.PointSize])
+	if err != nil {
+		return err
+	}
+	if err := proof.Proof.UnmarshalBinary(data[curveutil.PointSize : PaymentProofSize-1]); err != nil {
+		return err
+	}
+	rest := data[PaymentProofSize:]
+	switch data[PaymentProofSize-1] {
+	case 0:
+		if len(rest) != 0 {
+			return ErrInvalidPayment
+		}
+		proof.Amount = nil
+	case 1:
+		d := new(confamount.SenderDisclosure)
+		if err := d.UnmarshalBinary(rest); err != nil {
+			return err
+		}
+		proof.Amount = d
+	default:
+		return ErrInvalidPayment
+	}
+	proof.Shared = shared
+	return nil
+}
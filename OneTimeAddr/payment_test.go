@@ -0,0 +1,150 @@
+package onetimeaddr
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/ConfAmount"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// testPaymentAmount 以 pk_r 加密金额 m 的输出与发送方的加密随机数
+func testPaymentAmount(t *testing.T, a *testAddr, m uint64) *PaymentAmount {
+	t.Helper()
+	params := confamount.DefaultParams()
+	r := mustScalar(t)
+	ct := confamount.EncryptWithRandomness(&params, &a.pkR, new(big.Int).SetUint64(m), r)
+	return &PaymentAmount{AmountOutput: AmountOutput{Params: &params, PK: a.pkR, Ct: *ct}, M: m, R: r}
+}
+
+func TestPayment(t *testing.T) {
+	a := newTestAddr(t)
+	ota := &a.witness.Ota
+	ctx := []byte("dispute 1")
+	amount := testPaymentAmount(t, a, 500)
+
+	plain, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, nil, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, nil, plain, ctx); err != nil {
+		t.Fatal(err)
+	}
+	withAmount, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, amount, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, &amount.AmountOutput, withAmount, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if withAmount.Amount.M != 500 {
+		t.Fatalf("disclosed %d, want 500", withAmount.Amount.M)
+	}
+
+	if _, err := ProvePayment(rand.Reader, a.params, mustScalar(t), &a.pkR, ota, nil, ctx); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("wrong r_t: got %v, want ErrInvalidWitness", err)
+	}
+}
+
+// 金额输出与金额披露只有一方给出时必须拒绝
+func TestPaymentOneSidedAmount(t *testing.T) {
+	a := newTestAddr(t)
+	ota := &a.witness.Ota
+	amount := testPaymentAmount(t, a, 500)
+
+	plain, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, &amount.AmountOutput, plain, nil); !errors.Is(err, ErrInvalidPayment) {
+		t.Fatalf("amount output without disclosure: got %v, want ErrInvalidPayment", err)
+	}
+	withAmount, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, amount, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, nil, withAmount, nil); !errors.Is(err, ErrInvalidPayment) {
+		t.Fatalf("disclosure without amount output: got %v, want ErrInvalidPayment", err)
+	}
+}
+
+func TestPaymentRejected(t *testing.T) {
+	a := newTestAddr(t)
+	other := newTestAddr(t)
+	ota := &a.witness.Ota
+	ctx := []byte("dispute 1")
+	amount := testPaymentAmount(t, a, 500)
+	proof, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, amount, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 换一个付款金额密文
+	swapped := testPaymentAmount(t, a, 500)
+	cases := []struct {
+		name    string
+		Rt      *twistededwards.PointAffine
+		pkR     *twistededwards.PointAffine
+		ota     *twistededwards.PointAffine
+		amount  *AmountOutput
+		context []byte
+	}{
+		{"other Rt", &other.Rt, &a.pkR, ota, &amount.AmountOutput, ctx},
+		{"other pk_r", &a.Rt, &other.pkR, ota, &amount.AmountOutput, ctx},
+		{"other ota", &a.Rt, &a.pkR, &other.witness.Ota, &amount.AmountOutput, ctx},
+		{"other amount output", &a.Rt, &a.pkR, ota, &swapped.AmountOutput, ctx},
+		{"other context", &a.Rt, &a.pkR, ota, &amount.AmountOutput, []byte("dispute 2")},
+	}
+	for _, c := range cases {
+		if err := VerifyPayment(a.params, c.Rt, c.pkR, c.ota, c.amount, proof, c.context); !errors.Is(err, ErrInvalidPayment) {
+			t.Fatalf("%s: got %v, want ErrInvalidPayment", c.name, err)
+		}
+	}
+
+	lie := *proof
+	lie.Amount = &confamount.SenderDisclosure{M: 501, Proof: proof.Amount.Proof}
+	if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, &amount.AmountOutput, &lie, ctx); !errors.Is(err, ErrInvalidPayment) {
+		t.Fatalf("changed amount: got %v, want ErrInvalidPayment", err)
+	}
+}
+
+func TestPaymentMarshal(t *testing.T) {
+	a := newTestAddr(t)
+	ota := &a.witness.Ota
+	amount := testPaymentAmount(t, a, 42)
+	for _, amt := range []*PaymentAmount{nil, amount} {
+		proof, err := ProvePayment(rand.Reader, a.params, a.rt, &a.pkR, ota, amt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded PaymentProof
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		var out *AmountOutput
+		if amt != nil {
+			out = &amt.AmountOutput
+		}
+		if err := VerifyPayment(a.params, &a.Rt, &a.pkR, ota, out, &decoded, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, bad := range [][]byte{data[:len(data)-1], append(append([]byte(nil), data...), 0)} {
+			if err := new(PaymentProof).UnmarshalBinary(bad); err == nil {
+				t.Fatalf("accepted %d of %d bytes", len(bad), len(data))
+			}
+		}
+		flag := append([]byte(nil), data...)
+		flag[PaymentProofSize-1] = 2
+		if err := new(PaymentProof).UnmarshalBinary(flag); !errors.Is(err, ErrInvalidPayment) {
+			t.Fatalf("unknown flag: got %v, want ErrInvalidPayment", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package onetimeaddr
+
+import (
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// recoveryDomain 监管恢复证明挑战的域分隔标签
+const recoveryDomain = "LYcode/OneTimeAddr/Recovery/v1"
+
+// RecoveryProof 证明恢复出的 pk_r = C2 − sk_rev·C1，且 sk_rev 与 pk_rev 对应：
+// 即 pk_rev = sk_rev·G 与 C2 − pk_r = sk_rev·C1 的 DLEQ 证明
+type RecoveryProof struct {
+	curveutil.DLEQ
+}
+
+// Recover 监管方用 sk_rev 从 (C1, C2) 恢复接收方公钥 pk_r，并给出可公开验证的证明
+func Recover(rand io.Reader, params *Params, skRev *big.Int, statement *Statement) (twistededwards.PointAffine, *RecoveryProof, error) {
+	var pkRev twistededwards.PointAffine
+	pkRev.ScalarMultiplication(&params.G, skRev)
+	if !pkRev.Equal(&params.PkRev) {
+		return twistededwards.PointAffine{}, nil, ErrInvalidWitness
+	}
+
+	// pk_r = C2 − sk_rev·C1
+	var pkR, shared twistededwards.PointAffine
+	shared.ScalarMultiplication(&statement.C1, skRev)
+	pkR.Neg(&shared)
+	pkR.Add(&statement.C2, &pkR)
+
+	dleq, err := curveutil.ProveDLEQ(rand, recoveryDomain, &params.G, &params.PkRev, &statement.C1, &shared, skRev, statement.Ota.Marshal(), pkR.Marshal())
+	if err != nil {
+		return twistededwards.PointAffine{}, nil, err
+	}
+	return pkR, &RecoveryProof{*dleq}, nil
+}
+
+// VerifyRecover 不需要私钥，验证 pk_r 是 (C1, C2) 在 pk_rev 下的正确解密
+func VerifyRecover(params *Params, statement *Statement, pkR *twistededwards.PointAffine, proof *RecoveryProof) error {
+	// C2 − pk_r 应为 sk_rev·C1
+	var shared twistededwards.PointAffine
+	shared.Neg(pkR)
+	shared.Add(&statement.C2, &shared)
+	if !proof.Verify(recoveryDomain, &params.G, &params.PkRev, &statement.C1, &shared, statement.Ota.Marshal(), pkR.Marshal()) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package onetimeaddr
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	a := newTestAddr(t)
+	st := &a.witness.Statement
+	pkR, proof, err := Recover(rand.Reader, a.params, a.skRev, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pkR.Equal(&a.pkR) {
+		t.Fatal("recovered a different pk_r")
+	}
+	if err := VerifyRecover(a.params, st, &pkR, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Recover(rand.Reader, a.params, a.skR, st); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("wrong sk_rev: got %v, want ErrInvalidWitness", err)
+	}
+
+	// 声称另一个公钥、篡改证明或换一个陈述都不能通过
+	other := newTestAddr(t)
+	if err := VerifyRecover(a.params, st, &other.pkR, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("wrong pk_r: got %v, want ErrInvalidProof", err)
+	}
+	tampered := &RecoveryProof{}
+	tampered.C.Set(&proof.C)
+	tampered.Z.Add(&proof.Z, big.NewInt(1))
+	if err := VerifyRecover(a.params, st, &pkR, tampered); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("tampered proof: got %v, want ErrInvalidProof", err)
+	}
+	moved := *st
+	moved.Ota = other.witness.Ota
+	if err := VerifyRecover(a.params, &moved, &pkR, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("other ota: got %v, want ErrInvalidProof", err)
+	}
+}
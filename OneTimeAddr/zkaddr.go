@@ -0,0 +1,131 @@
+// Package onetimeaddr 实现一次性地址方案中的监管与付款相关证明。
+package onetimeaddr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// zkAddrDomain ZkAddrProof 挑战的域分隔标签
+const zkAddrDomain = "LYcode/ZkAddrProof/v2"
+
+// ZkAddrProofSize ZkAddrProof 序列化后的长度
+const ZkAddrProofSize = 3 * curveutil.ScalarSize
+
+var (
+	ErrInvalidWitness = errors.New("onetimeaddr: witness does not match statement")
+	ErrInvalidProof   = errors.New("onetimeaddr: invalid proof")
+)
+
+// Params 公共参数：基点 G 与监管方公钥 pk_rev
+type Params struct {
+	G     twistededwards.PointAffine
+	PkRev twistededwards.PointAffine
+}
+
+// Statement 待证明的陈述：一次性地址 ota 与监管密文 (C1, C2)
+type Statement struct {
+	Ota twistededwards.PointAffine
+	C1  twistededwards.PointAffine
+	C2  twistededwards.PointAffine
+}
+
+// Witness 证据：C1 = u·G，C2 = u·pk_rev + pk_r，ota = t·G + pk_r
+type Witness struct {
+	Statement
+	U *big.Int
+	T *big.Int
+}
+
+// ZkAddrProof 证明监管密文 (C1, C2) 与 ota 中的接收方公钥一致
+type ZkAddrProof struct {
+	C  big.Int // 挑战
+	W1 big.Int // r_u + c·u
+	Wt big.Int // r_t + c·t
+}
+
+// ProveAddr 生成 ZkAddrProof
+func ProveAddr(rand io.Reader, params *Params, witness *Witness) (*ZkAddrProof, error) {
+	if witness.U == nil || witness.T == nil {
+		return nil, ErrInvalidWitness
+	}
+	// 承诺使用框架内独立的随机数，不会覆盖交易私钥 r_t
+	proof, err := AddrRelation(params, &witness.Statement).Prove(rand, []*big.Int{witness.U, witness.T})
+	if errors.Is(err, sigmaproof.ErrInvalidWitness) {
+		return nil, ErrInvalidWitness
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := new(ZkAddrProof)
+	res.C.Set(&proof.C)
+	res.W1.Set(&proof.Z[0])
+	res.Wt.Set(&proof.Z[1])
+	return res, nil
+}
+
+// VerifyAddr 验证 ZkAddrProof
+func VerifyAddr(params *Params, statement *Statement, proof *ZkAddrProof) error {
+	sp := &sigmaproof.Proof{Z: []big.Int{proof.W1, proof.Wt}}
+	sp.C.Set(&proof.C)
+	if AddrRelation(params, statement).Verify(sp) != nil {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// AddrRelation 以 (u, t) 为秘密标量声明 ZkAddrProof 的关系：
+//
+//	C1 = u·G，C2 − ota = u·pk_rev + t·(−G)
+//
+// 其 Simulate 即 ZkAddrProof 的诚实验证者零知识模拟器
+func AddrRelation(params *Params, statement *Statement) *sigmaproof.Relation {
+	var lhs, negG twistededwards.PointAffine
+	lhs.Neg(&statement.Ota)
+	lhs.Add(&statement.C2, &lhs)
+	negG.Neg(&params.G)
+
+	r := sigmaproof.New(zkAddrDomain)
+	G := r.Point(params.G)
+	pkRev := r.Point(params.PkRev)
+	nG := r.Point(negG)
+	C1 := r.Point(statement.C1)
+	D := r.Point(lhs)
+	// ota 与 C2 经由 D 进入挑战，这里单独加入 ota 以绑定两者
+	r.Point(statement.Ota)
+	u, t := r.Scalar(), r.Scalar()
+	r.Equation(C1, sigmaproof.Term{X: u, P: G})
+	r.Equation(D, sigmaproof.Term{X: u, P: pkRev}, sigmaproof.Term{X: t, P: nG})
+	return r
+}
+
+// MarshalBinary 序列化为 c || w1 || wt
+func (proof *ZkAddrProof) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 0, ZkAddrProofSize)
+	for _, k := range []*big.Int{&proof.C, &proof.W1, &proof.Wt} {
+		b := curveutil.ScalarBytes(k)
+		res = append(res, b[:]...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *ZkAddrProof) UnmarshalBinary(data []byte) error {
+	if len(data) != ZkAddrProofSize {
+		return ErrInvalidProof
+	}
+	for i, k := range []*big.Int{&proof.C, &proof.W1, &proof.Wt} {
+		v, err := curveutil.ScalarFromBytes(data[i*curveutil.ScalarSize : (i+1)*curveutil.ScalarSize])
+		if err != nil {
+			return err
+		}
+		k.Set(v)
+	}
+	return nil
+}
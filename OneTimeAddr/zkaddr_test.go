@@ -0,0 +1,175 @@
+package onetimeaddr
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"testing/iotest"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// seededReader 以 SHA-256(seed || counter) 生成确定性字节流，用于复现测试向量
+type seededReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			block := sha256.Sum256(binary.BigEndian.AppendUint64(append([]byte(nil), r.seed...), r.counter))
+			r.buf = block[:]
+			r.counter++
+		}
+		k := copy(p[n:], r.buf)
+		r.buf = r.buf[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// knownAnswerSeed 已知答案测试的域标签，同时作为 seededReader 的种子
+const knownAnswerSeed = "LYcode/KnownAnswer/v1"
+
+// knownAnswerVector 以 knownAnswerSeed 为种子时 knownAnswerAddrProof 的输出（c || w1 || wt）
+const knownAnswerVector = "05384b86cda9378381091f321f00dbe97db80d3d2f6e1711f6b278d0c4fb5944" +
+	"056c111f00a5114b49893ee6ffb2fa138ffc957812d546206c3e865f31017fb5" +
+	"02f62adaab93205751b6103ca38b077308e1270ed751fac9bb2f7a7b6f44e0ce"
+
+// knownAnswerStatement 由固定的 sk_rev、sk_r 与证据 (u, t) 构造 ZkAddrProof 的参数与证据
+func knownAnswerStatement() (*Params, *Witness) {
+	curve := twistededwards.GetEdwardsCurve()
+	scalar := func(label string) *big.Int {
+		return curveutil.HashToScalar(knownAnswerSeed, []byte(label))
+	}
+	skRev, skR, u, t := scalar("sk_rev"), scalar("sk_r"), scalar("u"), scalar("t")
+
+	var pkRev, pkR, tG twistededwards.PointAffine
+	pkRev.ScalarMultiplication(&curve.Base, skRev)
+	pkR.ScalarMultiplication(&curve.Base, skR)
+	tG.ScalarMultiplication(&curve.Base, t)
+	w := &Witness{U: u, T: t}
+	w.C1.ScalarMultiplication(&curve.Base, u)
+	w.C2.ScalarMultiplication(&pkRev, u)
+	w.C2.Add(&w.C2, &pkR)
+	w.Ota.Add(&tG, &pkR)
+	return &Params{G: curve.Base, PkRev: pkRev}, w
+}
+
+// knownAnswerAddrProof 对固定陈述用 rand 生成 ZkAddrProof 并序列化
+func knownAnswerAddrProof(rand io.Reader) ([]byte, error) {
+	params, w := knownAnswerStatement()
+	proof, err := ProveAddr(rand, params, w)
+	if err != nil {
+		return nil, err
+	}
+	return proof.MarshalBinary()
+}
+
+// 固定证据与种子下 ZkAddrProof 的序列化必须与已知答案向量逐字节一致
+func TestKnownAnswerAddrProof(t *testing.T) {
+	got, err := knownAnswerAddrProof(&seededReader{seed: []byte(knownAnswerSeed)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(got) != knownAnswerVector {
+		t.Fatalf("got %x, want %s", got, knownAnswerVector)
+	}
+	var proof ZkAddrProof
+	if err := proof.UnmarshalBinary(got); err != nil {
+		t.Fatal(err)
+	}
+	params, w := knownAnswerStatement()
+	if err := VerifyAddr(params, &w.Statement, &proof); err != nil {
+		t.Fatal(err)
+	}
+
+	// 换一个种子得到不同的证明
+	other, err := knownAnswerAddrProof(&seededReader{seed: []byte("other")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(other) == knownAnswerVector {
+		t.Fatal("proof does not depend on the seed")
+	}
+}
+
+func TestKnownAnswerEntropyError(t *testing.T) {
+	errEntropy := errors.New("entropy source unavailable")
+	if _, err := knownAnswerAddrProof(iotest.ErrReader(errEntropy)); !errors.Is(err, errEntropy) {
+		t.Fatalf("got %v, want %v", err, errEntropy)
+	}
+}
+
+// testAddr 按一次性地址方案生成的一组密钥与陈述：ota = H(r_t·pk_r)·G + pk_r，(C1, C2) 为 pk_r 在 pk_rev 下的密文
+type testAddr struct {
+	params    *Params
+	witness   *Witness
+	skRev     *big.Int
+	skR       *big.Int
+	pkR       twistededwards.PointAffine
+	rt        *big.Int
+	Rt        twistededwards.PointAffine
+	otaSecret *big.Int // ota 的私钥 t + sk_r
+}
+
+func mustScalar(t *testing.T) *big.Int {
+	t.Helper()
+	k, err := curveutil.RandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newTestAddr(t *testing.T) *testAddr {
+	t.Helper()
+	curve := twistededwards.GetEdwardsCurve()
+	a := &testAddr{skRev: mustScalar(t), skR: mustScalar(t), rt: mustScalar(t)}
+	var pkRev, shared twistededwards.PointAffine
+	pkRev.ScalarMultiplication(&curve.Base, a.skRev)
+	a.pkR.ScalarMultiplication(&curve.Base, a.skR)
+	a.Rt.ScalarMultiplication(&curve.Base, a.rt)
+	shared.ScalarMultiplication(&a.pkR, a.rt)
+	digest := sha256.Sum256(shared.Marshal())
+	tt := new(big.Int).SetBytes(digest[:])
+
+	u := mustScalar(t)
+	w := &Witness{U: u, T: tt}
+	w.Ota.ScalarMultiplication(&curve.Base, tt)
+	w.Ota.Add(&w.Ota, &a.pkR)
+	w.C1.ScalarMultiplication(&curve.Base, u)
+	w.C2.ScalarMultiplication(&pkRev, u)
+	w.C2.Add(&w.C2, &a.pkR)
+	a.params = &Params{G: curve.Base, PkRev: pkRev}
+	a.witness = w
+	a.otaSecret = curveutil.ModOrder(new(big.Int).Add(tt, a.skR))
+	return a
+}
+
+// 回卷 AddrRelation 的证明者必须提取出 (u, t)
+func TestAddrRelationExtract(t *testing.T) {
+	a := newTestAddr(t)
+	p, err := AddrRelation(a.params, &a.witness.Statement).NewProver(rand.Reader, []*big.Int{a.witness.U, a.witness.T})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := sigmaproof.Rewind(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Cmp(a.witness.U) != 0 || got[1].Cmp(curveutil.ModOrder(a.witness.T)) != 0 {
+		t.Fatalf("extracted (%v, %v), want (%v, %v)", got[0], got[1], a.witness.U, a.witness.T)
+	}
+}
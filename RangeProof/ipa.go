@@ -0,0 +1,65 @@
+package rangeproof
+
+import (
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// proveInnerProduct 证明 P = <a, G> + <b, H> + <a, b>·U，每轮折半：
+//
+//	L = <a_lo, G_hi> + <b_hi, H_lo> + c_L·U，R = <a_hi, G_lo> + <b_lo, H_hi> + c_R·U
+//	G' = u^{-1}·G_lo + u·G_hi，H' = u·H_lo + u^{-1}·H_hi
+//	a' = u·a_lo + u^{-1}·a_hi，b' = u^{-1}·b_lo + u·b_hi
+//
+// 返回各轮 L、R 及长度为 1 的 a、b。
+func proveInnerProduct(ts *transcript, G, H []twistededwards.PointAffine, U *twistededwards.PointAffine, a, b []*big.Int) (L, R []twistededwards.PointAffine, aOut, bOut []*big.Int) {
+	for n := len(a); n > 1; n /= 2 {
+		h := n / 2
+		cL := innerProduct(a[:h], b[h:])
+		cR := innerProduct(a[h:], b[:h])
+
+		pts := append(append(append([]twistededwards.PointAffine(nil), G[h:n]...), H[:h]...), *U)
+		Lk := curveutil.MultiScalarMul(pts, append(append(append([]*big.Int(nil), a[:h]...), b[h:n]...), cL))
+		pts = append(append(append([]twistededwards.PointAffine(nil), G[:h]...), H[h:n]...), *U)
+		Rk := curveutil.MultiScalarMul(pts, append(append(append([]*big.Int(nil), a[h:n]...), b[:h]...), cR))
+		L = append(L, Lk)
+		R = append(R, Rk)
+
+		ts.appendPoints("L,R", Lk, Rk)
+		u := ts.challenge("u")
+		uInv := modInverse(u)
+
+		for i := 0; i < h; i++ {
+			G[i] = curveutil.MultiScalarMul([]twistededwards.PointAffine{G[i], G[h+i]}, []*big.Int{uInv, u})
+			H[i] = curveutil.MultiScalarMul([]twistededwards.PointAffine{H[i], H[h+i]}, []*big.Int{u, uInv})
+			a[i] = modAdd(modMul(u, a[i]), modMul(uInv, a[h+i]))
+			b[i] = modAdd(modMul(uInv, b[i]), modMul(u, b[h+i]))
+		}
+		G, H, a, b = G[:h], H[:h], a[:h], b[:h]
+	}
+	return L, R, a, b
+}
+
+// ipaScalars 返回 s_i = Π_k u_k^{±1}，第 k 轮对应 i 的第 k 高位，该位为 1 取 u_k，否则取 u_k^{-1}
+func ipaScalars(u []*big.Int, n int) []*big.Int {
+	rounds := len(u)
+	uInv := make([]*big.Int, rounds)
+	for k := range u {
+		uInv[k] = modInverse(u[k])
+	}
+	s := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		s[i] = big.NewInt(1)
+		for k := 0; k < rounds; k++ {
+			if (i>>uint(rounds-1-k))&1 == 1 {
+				s[i] = modMul(s[i], u[k])
+			} else {
+				s[i] = modMul(s[i], uInv[k])
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package rangeproof
+
+import (
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// MarshalBinary 序列化为 rounds || A, S, T1, T2 || τx, μ, t̂, a, b || L_1, R_1, ...
+func (proof *Proof) MarshalBinary() ([]byte, error) {
+	rounds := len(proof.L)
+	res := make([]byte, 1, 1+(4+2*rounds)*curveutil.PointSize+5*curveutil.ScalarSize)
+	res[0] = byte(rounds)
+	for _, p := range []*twistededwards.PointAffine{&proof.A, &proof.S, &proof.T1, &proof.T2} {
+		res = append(res, p.Marshal()...)
+	}
+	for _, k := range []*big.Int{&proof.TauX, &proof.Mu, &proof.THat, &proof.IPA, &proof.IPB} {
+		b := curveutil.ScalarBytes(k)
+		res = append(res, b[:]...)
+	}
+	for k := 0; k < rounds; k++ {
+		res = append(res, proof.L[k].Marshal()...)
+		res = append(res, proof.R[k].Marshal()...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明，并检查各点合法
+func (proof *Proof) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return ErrInvalidProof
+	}
+	rounds := int(data[0])
+	if len(data) != 1+(4+2*rounds)*curveutil.PointSize+5*curveutil.ScalarSize {
+		return ErrInvalidProof
+	}
+	off := 1
+	readPoint := func(p *twistededwards.PointAffine) error {
+		v, err := curveutil.PointFromBytes(data[off : off+curveutil.PointSize])
+		off += curveutil.PointSize
+		*p = v
+		return err
+	}
+	readScalar := func(k *big.Int) error {
+		v, err := curveutil.ScalarFromBytes(data[off : off+curveutil.ScalarSize])
+		off += curveutil.ScalarSize
+		if err == nil {
+			k.Set(v)
+		}
+		return err
+	}
+
+	for _, p := range []*twistededwards.PointAffine{&proof.A, &proof.S, &proof.T1, &proof.T2} {
+		if err := readPoint(p); err != nil {
+			return err
+		}
+	}
+	for _, k := range []*big.Int{&proof.TauX, &proof.Mu, &proof.THat, &proof.IPA, &proof.IPB} {
+		if err := readScalar(k); err != nil {
+			return err
+		}
+	}
+	proof.L = make([]twistededwards.PointAffine, rounds)
+	proof.R = make([]twistededwards.PointAffine, rounds)
+	for k := 0; k < rounds; k++ {
+		if err := readPoint(&proof.L[k]); err != nil {
+			return err
+		}
+		if err := readPoint(&proof.R[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
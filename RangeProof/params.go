@@ -0,0 +1,72 @@
+// Package rangeproof 实现 bn254 twisted Edwards 群上的 Bulletproofs 聚合范围证明，
+// 证明每个金额承诺 Y_i = r_i·G + m_i·h 中的 m_i 属于 [0, 2^n)。
+package rangeproof
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// generatorDomain 向量生成元的哈希域
+const generatorDomain = "LYcode/RangeProof/generators"
+
+var (
+	ErrInvalidParams = errors.New("rangeproof: invalid parameters")
+	ErrValueRange    = errors.New("rangeproof: value out of range")
+	ErrInvalidProof  = errors.New("rangeproof: invalid proof")
+)
+
+// Params 范围证明公共参数
+type Params struct {
+	G  twistededwards.PointAffine   // 盲化因子基点
+	H  twistededwards.PointAffine   // 金额基点 h
+	U  twistededwards.PointAffine   // 内积证明基点
+	Gs []twistededwards.PointAffine // 长度 Bits·MaxAggregation
+	Hs []twistededwards.PointAffine
+
+	Bits           int // 每个金额的比特数 n
+	MaxAggregation int // 单个证明最多聚合的承诺个数
+}
+
+// NewParams 生成 n 比特、最多聚合 maxAggregation 个承诺的参数。
+// G、h 需与金额加密使用的基点一致；向量生成元由哈希到曲线生成。
+func NewParams(G, H twistededwards.PointAffine, n, maxAggregation int) (*Params, error) {
+	if n <= 0 || n > 64 || !isPowerOfTwo(n) || maxAggregation <= 0 {
+		return nil, ErrInvalidParams
+	}
+	maxAggregation = nextPowerOfTwo(maxAggregation)
+	size := n * maxAggregation
+
+	params := &Params{
+		G:              G,
+		H:              H,
+		U:              curveutil.HashToPoint(generatorDomain, []byte("U")),
+		Gs:             make([]twistededwards.PointAffine, size),
+		Hs:             make([]twistededwards.PointAffine, size),
+		Bits:           n,
+		MaxAggregation: maxAggregation,
+	}
+	var idx [8]byte
+	for i := 0; i < size; i++ {
+		binary.BigEndian.PutUint64(idx[:], uint64(i))
+		params.Gs[i] = curveutil.HashToPoint(generatorDomain, []byte("G"), idx[:])
+		params.Hs[i] = curveutil.HashToPoint(generatorDomain, []byte("H"), idx[:])
+	}
+	return params, nil
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
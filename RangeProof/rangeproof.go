@@ -0,0 +1,331 @@
+package rangeproof
+
+import (
+	crand "crypto/rand"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// Proof 聚合范围证明，大小为 O(log(n·m))
+type Proof struct {
+	A, S, T1, T2 twistededwards.PointAffine
+	TauX, Mu     big.Int
+	THat         big.Int
+	L, R         []twistededwards.PointAffine // 内积证明各轮的 L_k, R_k
+	IPA, IPB     big.Int                      // 内积证明最终的 a, b
+}
+
+// Commit 计算承诺 γ·G + v·h，与金额密文的 Y 分量一致
+func (params *Params) Commit(v uint64, gamma *big.Int) twistededwards.PointAffine {
+	return curveutil.MultiScalarMul(
+		[]twistededwards.PointAffine{params.G, params.H},
+		[]*big.Int{gamma, new(big.Int).SetUint64(v)},
+	)
+}
+
+// Prove 为 values[j]（承诺为 gammas[j]·G + values[j]·h）生成一个聚合范围证明
+func Prove(rand io.Reader, params *Params, values []uint64, gammas []*big.Int) (*Proof, error) {
+	m := len(values)
+	if m == 0 || m > params.MaxAggregation || len(gammas) != m {
+		return nil, ErrInvalidParams
+	}
+	n := params.Bits
+	for _, v := range values {
+		if n < 64 && v>>uint(n) != 0 {
+			return nil, ErrValueRange
+		}
+	}
+
+	// 承诺个数补齐到 2 的幂，补位承诺为单位元
+	mPad := nextPowerOfTwo(m)
+	N := n * mPad
+	V := make([]twistededwards.PointAffine, mPad)
+	gs := make([]*big.Int, mPad)
+	vs := make([]uint64, mPad)
+	for j := 0; j < mPad; j++ {
+		if j < m {
+			V[j] = params.Commit(values[j], gammas[j])
+			gs[j], vs[j] = gammas[j], values[j]
+		} else {
+			V[j] = curveutil.Identity()
+			gs[j] = new(big.Int)
+		}
+	}
+
+	ts := newTranscript()
+	ts.appendScalars("n,m", big.NewInt(int64(n)), big.NewInt(int64(mPad)))
+	ts.appendPoints("V", V...)
+
+	// a_L 为各金额的比特，a_R = a_L − 1
+	aL := make([]*big.Int, N)
+	aR := make([]*big.Int, N)
+	for j := 0; j < mPad; j++ {
+		for k := 0; k < n; k++ {
+			bit := int64(vs[j] >> uint(k) & 1)
+			aL[j*n+k] = big.NewInt(bit)
+			aR[j*n+k] = big.NewInt(bit - 1)
+		}
+	}
+	sL, err := randomScalars(rand, N)
+	if err != nil {
+		return nil, err
+	}
+	sR, err := randomScalars(rand, N)
+	if err != nil {
+		return nil, err
+	}
+	blinds, err := randomScalars(rand, 4)
+	if err != nil {
+		return nil, err
+	}
+	alpha, rho, tau1, tau2 := blinds[0], blinds[1], blinds[2], blinds[3]
+
+	proof := new(Proof)
+	gens := append([]twistededwards.PointAffine{params.G}, params.Gs[:N]...)
+	gens = append(gens, params.Hs[:N]...)
+	proof.A = curveutil.MultiScalarMul(gens, append(append([]*big.Int{alpha}, aL...), aR...))
+	proof.S = curveutil.MultiScalarMul(gens, append(append([]*big.Int{rho}, sL...), sR...))
+	ts.appendPoints("A,S", proof.A, proof.S)
+	y := ts.challenge("y")
+	z := ts.challenge("z")
+
+	yPow := powers(y, N)
+	zPow := powers(z, mPad+3)
+	w := weights(zPow, n, mPad)
+
+	// l(X) = l0 + l1·X，r(X) = r0 + r1·X
+	l0 := make([]*big.Int, N)
+	r0 := make([]*big.Int, N)
+	r1 := make([]*big.Int, N)
+	for i := 0; i < N; i++ {
+		l0[i] = modSub(aL[i], z)
+		r0[i] = modAdd(modMul(yPow[i], modAdd(aR[i], z)), w[i])
+		r1[i] = modMul(yPow[i], sR[i])
+	}
+	t1 := modAdd(innerProduct(l0, r1), innerProduct(sL, r0))
+	t2 := innerProduct(sL, r1)
+
+	proof.T1 = curveutil.MultiScalarMul([]twistededwards.PointAffine{params.H, params.G}, []*big.Int{t1, tau1})
+	proof.T2 = curveutil.MultiScalarMul([]twistededwards.PointAffine{params.H, params.G}, []*big.Int{t2, tau2})
+	ts.appendPoints("T1,T2", proof.T1, proof.T2)
+	x := ts.challenge("x")
+
+	l := make([]*big.Int, N)
+	r := make([]*big.Int, N)
+	for i := 0; i < N; i++ {
+		l[i] = modAdd(l0[i], modMul(sL[i], x))
+		r[i] = modAdd(r0[i], modMul(r1[i], x))
+	}
+	proof.THat.Set(innerProduct(l, r))
+
+	// τx = τ2·x² + τ1·x + Σ z^{2+j}·γ_j，μ = α + ρ·x
+	tauX := modAdd(modMul(tau2, modMul(x, x)), modMul(tau1, x))
+	for j := 0; j < mPad; j++ {
+		tauX = modAdd(tauX, modMul(zPow[2+j], gs[j]))
+	}
+	proof.TauX.Set(tauX)
+	proof.Mu.Set(modAdd(alpha, modMul(rho, x)))
+	ts.appendScalars("tauX,mu,tHat", &proof.TauX, &proof.Mu, &proof.THat)
+	wIP := ts.challenge("w")
+
+	// H'_i = y^{-i}·H_i，U' = w·U
+	yInv := modInverse(y)
+	yInvPow := powers(yInv, N)
+	Hp := make([]twistededwards.PointAffine, N)
+	for i := 0; i < N; i++ {
+		Hp[i].ScalarMultiplication(&params.Hs[i], yInvPow[i])
+	}
+	var Up twistededwards.PointAffine
+	Up.ScalarMultiplication(&params.U, wIP)
+
+	Gs := append([]twistededwards.PointAffine(nil), params.Gs[:N]...)
+	proof.L, proof.R, l, r = proveInnerProduct(ts, Gs, Hp, &Up, l, r)
+	proof.IPA.Set(l[0])
+	proof.IPB.Set(r[0])
+	return proof, nil
+}
+
+// Verify 验证单个聚合范围证明，commitments[j] = γ_j·G + v_j·h
+func Verify(params *Params, commitments []twistededwards.PointAffine, proof *Proof) error {
+	return BatchVerify(params, [][]twistededwards.PointAffine{commitments}, []*Proof{proof})
+}
+
+// BatchVerify 用随机线性组合（系数取自 crypto/rand）将多个证明的验证方程合并为一次多标量乘法
+func BatchVerify(params *Params, commitments [][]twistededwards.PointAffine, proofs []*Proof) error {
+	if len(commitments) != len(proofs) || len(proofs) == 0 {
+		return ErrInvalidProof
+	}
+	acc := newAccumulator(params)
+	for i := range proofs {
+		beta, err := curveutil.RandomScalar(crand.Reader)
+		if err != nil {
+			return err
+		}
+		if err := acc.addProof(params, commitments[i], proofs[i], beta); err != nil {
+			return err
+		}
+	}
+	if res := acc.evaluate(params); !res.IsZero() {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// accumulator 累积验证方程中各点的系数
+type accumulator struct {
+	g, h, u *big.Int
+	gs, hs  []*big.Int
+	points  []twistededwards.PointAffine
+	scalars []*big.Int
+}
+
+func newAccumulator(params *Params) *accumulator {
+	acc := &accumulator{
+		g:  new(big.Int),
+		h:  new(big.Int),
+		u:  new(big.Int),
+		gs: make([]*big.Int, len(params.Gs)),
+		hs: make([]*big.Int, len(params.Hs)),
+	}
+	for i := range acc.gs {
+		acc.gs[i] = new(big.Int)
+		acc.hs[i] = new(big.Int)
+	}
+	return acc
+}
+
+func (acc *accumulator) add(p twistededwards.PointAffine, k *big.Int) {
+	acc.points = append(acc.points, p)
+	acc.scalars = append(acc.scalars, k)
+}
+
+func (acc *accumulator) evaluate(params *Params) twistededwards.PointAffine {
+	points := append([]twistededwards.PointAffine{params.G, params.H, params.U}, acc.points...)
+	scalars := append([]*big.Int{acc.g, acc.h, acc.u}, acc.scalars...)
+	points = append(points, params.Gs...)
+	points = append(points, params.Hs...)
+	scalars = append(scalars, acc.gs...)
+	scalars = append(scalars, acc.hs...)
+	return curveutil.MultiScalarMul(points, scalars)
+}
+
+// addProof 以权重 β 加入一个证明的两个验证方程：
+//
+//	ω·[(t̂ − δ)·h + τx·G − Σ z^{2+j}·V_j − x·T1 − x²·T2] = 0
+//	A + x·S − μ·G + Σ(−z − a·s_i)·G_i + Σ(z + (w_i − b·s_i^{-1})·y^{-i})·H_i + w·(t̂ − ab)·U + Σ(u_k²·L_k + u_k^{-2}·R_k) = 0
+func (acc *accumulator) addProof(params *Params, commitments []twistededwards.PointAffine, proof *Proof, beta *big.Int) error {
+	m := len(commitments)
+	if m == 0 || m > params.MaxAggregation {
+		return ErrInvalidProof
+	}
+	n := params.Bits
+	mPad := nextPowerOfTwo(m)
+	N := n * mPad
+	rounds := log2(N)
+	if len(proof.L) != rounds || len(proof.R) != rounds {
+		return ErrInvalidProof
+	}
+	V := make([]twistededwards.PointAffine, mPad)
+	copy(V, commitments)
+	for j := m; j < mPad; j++ {
+		V[j] = curveutil.Identity()
+	}
+
+	ts := newTranscript()
+	ts.appendScalars("n,m", big.NewInt(int64(n)), big.NewInt(int64(mPad)))
+	ts.appendPoints("V", V...)
+	ts.appendPoints("A,S", proof.A, proof.S)
+	y := ts.challenge("y")
+	z := ts.challenge("z")
+	ts.appendPoints("T1,T2", proof.T1, proof.T2)
+	x := ts.challenge("x")
+	ts.appendScalars("tauX,mu,tHat", &proof.TauX, &proof.Mu, &proof.THat)
+	wIP := ts.challenge("w")
+	u := make([]*big.Int, rounds)
+	for k := 0; k < rounds; k++ {
+		ts.appendPoints("L,R", proof.L[k], proof.R[k])
+		u[k] = ts.challenge("u")
+		if u[k].Sign() == 0 {
+			return ErrInvalidProof
+		}
+	}
+	if y.Sign() == 0 {
+		return ErrInvalidProof
+	}
+
+	omega, err := curveutil.RandomScalar(crand.Reader)
+	if err != nil {
+		return err
+	}
+	omega = modMul(omega, beta)
+
+	yPow := powers(y, N)
+	zPow := powers(z, mPad+3)
+	w := weights(zPow, n, mPad)
+
+	// δ(y, z) = (z − z²)·Σ y^i − Σ z^{3+j}·(2^n − 1)
+	sumY := new(big.Int)
+	for i := 0; i < N; i++ {
+		sumY = modAdd(sumY, yPow[i])
+	}
+	delta := modMul(modSub(z, zPow[2]), sumY)
+	twoN := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(n)), big.NewInt(1))
+	for j := 0; j < mPad; j++ {
+		delta = modSub(delta, modMul(zPow[3+j], twoN))
+	}
+
+	// 方程一
+	acc.h.Set(modAdd(acc.h, modMul(omega, modSub(&proof.THat, delta))))
+	acc.g.Set(modAdd(acc.g, modMul(omega, &proof.TauX)))
+	for j := 0; j < mPad; j++ {
+		acc.add(V[j], modNeg(modMul(omega, zPow[2+j])))
+	}
+	acc.add(proof.T1, modNeg(modMul(omega, x)))
+	acc.add(proof.T2, modNeg(modMul(omega, modMul(x, x))))
+
+	// 方程二
+	s := ipaScalars(u, N)
+	yInvPow := powers(modInverse(y), N)
+	acc.add(proof.A, beta)
+	acc.add(proof.S, modMul(beta, x))
+	acc.g.Set(modSub(acc.g, modMul(beta, &proof.Mu)))
+	for i := 0; i < N; i++ {
+		gi := modNeg(modAdd(z, modMul(&proof.IPA, s[i])))
+		hi := modMul(modSub(w[i], modMul(&proof.IPB, modInverse(s[i]))), yInvPow[i])
+		hi = modAdd(z, hi)
+		acc.gs[i].Set(modAdd(acc.gs[i], modMul(beta, gi)))
+		acc.hs[i].Set(modAdd(acc.hs[i], modMul(beta, hi)))
+	}
+	uCoeff := modMul(wIP, modSub(&proof.THat, modMul(&proof.IPA, &proof.IPB)))
+	acc.u.Set(modAdd(acc.u, modMul(beta, uCoeff)))
+	for k := 0; k < rounds; k++ {
+		u2 := modMul(u[k], u[k])
+		acc.add(proof.L[k], modMul(beta, u2))
+		acc.add(proof.R[k], modMul(beta, modInverse(u2)))
+	}
+	return nil
+}
+
+// weights 返回 w_{j·n+k} = z^{2+j}·2^k
+func weights(zPow []*big.Int, n, m int) []*big.Int {
+	w := make([]*big.Int, n*m)
+	for j := 0; j < m; j++ {
+		for k := 0; k < n; k++ {
+			w[j*n+k] = modMul(zPow[2+j], new(big.Int).Lsh(big.NewInt(1), uint(k)))
+		}
+	}
+	return w
+}
+
+// log2 返回 2 的幂 n 的以 2 为底对数
+func log2(n int) int {
+	k := 0
+	for 1<<k < n {
+		k++
+	}
+	return k
+}
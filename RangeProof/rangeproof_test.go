@@ -0,0 +1,226 @@
+package rangeproof
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// newTestParams 返回 64 比特、最多聚合 4 个承诺的参数，h 由哈希到曲线生成
+func newTestParams(t *testing.T) *Params {
+	t.Helper()
+	curve := twistededwards.GetEdwardsCurve()
+	params, err := NewParams(curve.Base, curveutil.HashToPoint("LYcode/RangeProof/test", []byte("h")), 64, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+// proveValues 为 values 生成随机盲化因子、承诺与聚合证明
+func proveValues(t *testing.T, params *Params, values []uint64) ([]twistededwards.PointAffine, *Proof) {
+	t.Helper()
+	gammas := make([]*big.Int, len(values))
+	commitments := make([]twistededwards.PointAffine, len(values))
+	for j, v := range values {
+		gamma, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gammas[j] = gamma
+		commitments[j] = params.Commit(v, gamma)
+	}
+	proof, err := Prove(rand.Reader, params, values, gammas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commitments, proof
+}
+
+func TestProveVerify(t *testing.T) {
+	params := newTestParams(t)
+	for _, values := range [][]uint64{
+		{0},
+		{1<<64 - 1, 42},
+		{7, 1 << 63, 0, 1<<64 - 1},
+	} {
+		commitments, proof := proveValues(t, params, values)
+		if err := Verify(params, commitments, proof); err != nil {
+			t.Fatalf("m=%d: %v", len(values), err)
+		}
+		// 轮数为 log2(64·m)
+		if want := log2(params.Bits * nextPowerOfTwo(len(values))); len(proof.L) != want {
+			t.Fatalf("m=%d: %d rounds, want %d", len(values), len(proof.L), want)
+		}
+	}
+}
+
+// 承诺 order − 5（即 −5）或 2^64 的金额时，证明者只能为截断到 64 比特的值作证，验证必须失败
+func TestOutOfRangeRejected(t *testing.T) {
+	params := newTestParams(t)
+	curve := twistededwards.GetEdwardsCurve()
+	twoTo64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	negFive := new(big.Int).Sub(&curve.Order, big.NewInt(5))
+
+	for name, v := range map[string]*big.Int{"order-5": negFive, "2^64": twoTo64} {
+		gamma, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitment := curveutil.MultiScalarMul([]twistededwards.PointAffine{params.G, params.H}, []*big.Int{gamma, v})
+		truncated := new(big.Int).Mod(v, twoTo64).Uint64()
+		proof, err := Prove(rand.Reader, params, []uint64{truncated}, []*big.Int{gamma})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(params, []twistededwards.PointAffine{commitment}, proof); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: got %v, want ErrInvalidProof", name, err)
+		}
+	}
+
+	// 比特数小于 64 时，超出 [0, 2^n) 的金额直接被 Prove 拒绝
+	small, err := NewParams(params.G, params.H, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Prove(rand.Reader, small, []uint64{256}, []*big.Int{big.NewInt(1)}); !errors.Is(err, ErrValueRange) {
+		t.Fatalf("got %v, want ErrValueRange", err)
+	}
+}
+
+func TestTamperedProofRejected(t *testing.T) {
+	params := newTestParams(t)
+	commitments, proof := proveValues(t, params, []uint64{10, 20})
+	G := params.G
+
+	for i := 0; i < 5; i++ {
+		forged := cloneProof(proof)
+		k := []*big.Int{&forged.TauX, &forged.Mu, &forged.THat, &forged.IPA, &forged.IPB}[i]
+		k.Set(curveutil.ModOrder(k.Add(k, big.NewInt(1))))
+		if err := Verify(params, commitments, forged); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("scalar %d: got %v, want ErrInvalidProof", i, err)
+		}
+	}
+	points := func(p *Proof) []*twistededwards.PointAffine {
+		ps := []*twistededwards.PointAffine{&p.A, &p.S, &p.T1, &p.T2}
+		for k := range p.L {
+			ps = append(ps, &p.L[k], &p.R[k])
+		}
+		return ps
+	}
+	for i := range points(proof) {
+		forged := cloneProof(proof)
+		p := points(forged)[i]
+		p.Add(p, &G)
+		if err := Verify(params, commitments, forged); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("point %d: got %v, want ErrInvalidProof", i, err)
+		}
+	}
+
+	if err := Verify(params, commitments, proof); err != nil {
+		t.Fatalf("original proof changed by tampering a copy: %v", err)
+	}
+
+	// 换成其他承诺、调换顺序或少给一个承诺
+	other, _ := proveValues(t, params, []uint64{10, 20})
+	swapped := []twistededwards.PointAffine{commitments[1], commitments[0]}
+	for name, cs := range map[string][]twistededwards.PointAffine{
+		"other":   other,
+		"swapped": swapped,
+		"short":   commitments[:1],
+	} {
+		if err := Verify(params, cs, proof); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: got %v, want ErrInvalidProof", name, err)
+		}
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	params := newTestParams(t)
+	var commitments [][]twistededwards.PointAffine
+	var proofs []*Proof
+	for _, values := range [][]uint64{{1}, {2, 3}, {4, 5, 6}} {
+		cs, proof := proveValues(t, params, values)
+		commitments = append(commitments, cs)
+		proofs = append(proofs, proof)
+	}
+	if err := BatchVerify(params, commitments, proofs); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := cloneProof(proofs[1])
+	bad.THat.Set(curveutil.ModOrder(new(big.Int).Add(&bad.THat, big.NewInt(1))))
+	if err := BatchVerify(params, commitments, []*Proof{proofs[0], bad, proofs[2]}); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("got %v, want ErrInvalidProof", err)
+	}
+	if err := BatchVerify(params, commitments[:2], proofs); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("mismatched lengths: got %v, want ErrInvalidProof", err)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	params := newTestParams(t)
+	commitments, proof := proveValues(t, params, []uint64{1, 2, 3})
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Proof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(params, commitments, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	again, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatal("re-encoding differs")
+	}
+
+	// 标量 τx 位于 1 + 4·PointSize 处，写成 ≥ order 的非规范编码
+	nonCanonical := append([]byte(nil), data...)
+	off := 1 + 4*curveutil.PointSize
+	for i := off; i < off+curveutil.ScalarSize; i++ {
+		nonCanonical[i] = 0xff
+	}
+	// 第一个点 A 换成曲线外的编码
+	badPoint := append([]byte(nil), data...)
+	for i := 1; i < 1+curveutil.PointSize; i++ {
+		badPoint[i] = 0xff
+	}
+	wrongRounds := append([]byte(nil), data...)
+	wrongRounds[0]++
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte(nil), data...), 0),
+		"rounds":    wrongRounds,
+		"scalar":    nonCanonical,
+		"point":     badPoint,
+	} {
+		if err := new(Proof).UnmarshalBinary(b); err == nil {
+			t.Fatalf("%s: accepted", name)
+		}
+	}
+}
+
+// cloneProof 深拷贝证明，篡改副本不影响原证明（直接复制结构体会与原 big.Int 共享底层数组）
+func cloneProof(p *Proof) *Proof {
+	c := &Proof{A: p.A, S: p.S, T1: p.T1, T2: p.T2}
+	c.TauX.Set(&p.TauX)
+	c.Mu.Set(&p.Mu)
+	c.THat.Set(&p.THat)
+	c.IPA.Set(&p.IPA)
+	c.IPB.Set(&p.IPB)
+	c.L = append([]twistededwards.PointAffine(nil), p.L...)
+	c.R = append([]twistededwards.PointAffine(nil), p.R...)
+	return c
+}
@@ -0,0 +1,70 @@
+package rangeproof
+
+import (
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// order 返回素数阶子群的阶
+func order() *big.Int {
+	curve := twistededwards.GetEdwardsCurve()
+	return &curve.Order
+}
+
+func modAdd(a, b *big.Int) *big.Int {
+	res := new(big.Int).Add(a, b)
+	return res.Mod(res, order())
+}
+
+func modSub(a, b *big.Int) *big.Int {
+	res := new(big.Int).Sub(a, b)
+	return res.Mod(res, order())
+}
+
+func modMul(a, b *big.Int) *big.Int {
+	res := new(big.Int).Mul(a, b)
+	return res.Mod(res, order())
+}
+
+func modNeg(a *big.Int) *big.Int {
+	res := new(big.Int).Neg(a)
+	return res.Mod(res, order())
+}
+
+func modInverse(a *big.Int) *big.Int {
+	return new(big.Int).ModInverse(a, order())
+}
+
+// powers 返回 [1, x, x², ..., x^{n-1}]
+func powers(x *big.Int, n int) []*big.Int {
+	res := make([]*big.Int, n)
+	cur := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		res[i] = cur
+		cur = modMul(cur, x)
+	}
+	return res
+}
+
+func innerProduct(a, b []*big.Int) *big.Int {
+	res := new(big.Int)
+	for i := range a {
+		res = modAdd(res, modMul(a[i], b[i]))
+	}
+	return res
+}
+
+func randomScalars(rand io.Reader, n int) ([]*big.Int, error) {
+	res := make([]*big.Int, n)
+	for i := range res {
+		var err error
+		if res[i], err = curveutil.RandomScalar(rand); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package rangeproof
+
+import (
+	"crypto/sha256"
+	"hash"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// transcriptDomain Fiat-Shamir 挑战的域分隔标签
+const transcriptDomain = "LYcode/RangeProof/v1"
+
+// transcript 按顺序吸收证明消息并导出挑战
+type transcript struct {
+	state hash.Hash
+}
+
+func newTranscript() *transcript {
+	t := &transcript{state: sha256.New()}
+	t.state.Write([]byte(transcriptDomain))
+	return t
+}
+
+func (t *transcript) appendPoints(label string, pts ...twistededwards.PointAffine) {
+	t.state.Write([]byte(label))
+	for i := range pts {
+		t.state.Write(pts[i].Marshal())
+	}
+}
+
+func (t *transcript) appendScalars(label string, ks ...*big.Int) {
+	t.state.Write([]byte(label))
+	for _, k := range ks {
+		b := curveutil.ScalarBytes(k)
+		t.state.Write(b[:])
+	}
+}
+
+// challenge 导出挑战并将其写回状态
+func (t *transcript) challenge(label string) *big.Int {
+	c := curveutil.HashToScalar(transcriptDomain, t.state.Sum(nil), []byte(label))
+	t.appendScalars(label, c)
+	return c
+}
@@ -0,0 +1,180 @@
+package recoverm2
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// BatchResult 批量求解结果：每个不同的目标点要么在 Found 中，要么在 Failed 中
+type BatchResult struct {
+	Found  map[twistededwards.PointAffine]*big.Int
+	Failed map[twistededwards.PointAffine]error // ErrNotFound、被取消时的 ctx.Err() 或袋鼠法随机源的错误
+}
+
+// SolveBatch 在 [lo, hi] 内对每个目标点求 k 使 k·base = target，单个目标失败不影响其余目标。
+// 线性搜索对所有目标只遍历一次区间；小步大步法共用一张表（未给出 Table 时按 ⌈√(T·n)⌉ 建表）；
+// 袋鼠法逐个求解。progress 的 done/total 对线性搜索为候选数，其余为已处理的目标数。
+// 只有区间或选项无效时返回 error。
+func SolveBatch(ctx context.Context, base twistededwards.PointAffine, targets []twistededwards.PointAffine, lo, hi *big.Int, progress ProgressFunc, opts *Options) (*BatchResult, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if lo.Sign() < 0 || hi.Cmp(lo) < 0 {
+		return nil, ErrInvalidRange
+	}
+	numThreads := opts.Threads
+	if numThreads <= 0 {
+		numThreads = runtime.NumCPU()
+	}
+	res := &BatchResult{
+		Found:  make(map[twistededwards.PointAffine]*big.Int),
+		Failed: make(map[twistededwards.PointAffine]error),
+	}
+
+	if opts.Algorithm == Kangaroo {
+		if hi.BitLen() > kangarooMaxBits {
+			return nil, ErrRangeTooWide
+		}
+		rep := startProgress(progress, uint64(len(targets)), opts.ProgressInterval)
+		for _, target := range targets {
+			if _, ok := res.Found[target]; ok {
+				rep.add(1)
+				continue
+			}
+			k, ok, err := kangarooSolve(ctx, opts.Rand, base, target, lo, hi, numThreads, nil, 0)
+			if err == nil {
+				k, err = solveResult(ctx, k, ok)
+			}
+			if err != nil {
+				res.Failed[target] = err
+			} else {
+				res.Found[target] = k
+			}
+			rep.add(1)
+		}
+		rep.finish()
+		return res, nil
+	}
+
+	width := new(big.Int).Sub(hi, lo)
+	if !width.IsUint64() || width.Uint64() == math.MaxUint64 {
+		return nil, ErrInvalidRange
+	}
+	n := width.Uint64() + 1
+
+	// 所有目标平移到 [0, hi − lo]：shifted = target − lo·base
+	var ind twistededwards.PointAffine
+	ind.ScalarMultiplication(&base, lo)
+	ind.Neg(&ind)
+	shifted := make(map[twistededwards.PointAffine]twistededwards.PointAffine, len(targets))
+	for _, target := range targets {
+		var s twistededwards.PointAffine
+		s.Add(&target, &ind)
+		shifted[s] = target
+	}
+
+	var found map[twistededwards.PointAffine]uint64
+	switch opts.Algorithm {
+	case Linear:
+		rep := startProgress(progress, n, opts.ProgressInterval)
+		found = searchLinearBatch(ctx, base, shifted, width.Uint64(), numThreads, rep)
+		rep.finish()
+	case BSGS:
+		table := opts.Table
+		if table == nil {
+			table = NewTable(base, BabySteps(satMul(n, uint64(len(shifted))), opts.BabySteps), numThreads)
+		} else if !table.base.Equal(&base) {
+			return nil, ErrTableBase
+		}
+		rep := startProgress(progress, uint64(len(shifted)), opts.ProgressInterval)
+		found = make(map[twistededwards.PointAffine]uint64)
+		inner := startProgress(nil, 0, 0)
+		for s := range shifted {
+			if ctx.Err() != nil {
+				break
+			}
+			if k, ok := table.plan(s, width.Uint64()).solve(ctx, numThreads, inner); ok {
+				found[s] = k
+			}
+			rep.add(1)
+		}
+		rep.finish()
+	default:
+		return nil, errUnknownAlgorithm
+	}
+
+	failure := ctx.Err()
+	if failure == nil {
+		failure = ErrNotFound
+	}
+	for s, target := range shifted {
+		k, ok := found[s]
+		if !ok {
+			res.Failed[target] = failure
+			continue
+		}
+		kb := new(big.Int).SetUint64(k)
+		res.Found[target] = kb.Add(kb, lo)
+	}
+	return res, nil
+}
+
+// searchLinearBatch 将 [0, width] 分段并行遍历一次，记录命中的目标；所有目标都命中后提前结束
+func searchLinearBatch(ctx context.Context, G twistededwards.PointAffine, targets map[twistededwards.PointAffine]twistededwards.PointAffine, width uint64, numThreads int, rep *progressReporter) map[twistededwards.PointAffine]uint64 {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	found := make(map[twistededwards.PointAffine]uint64, len(targets))
+	var remaining atomic.Int64
+	remaining.Store(int64(len(targets)))
+
+	var wg sync.WaitGroup
+	for _, c := range splitRange(0, width+1, numThreads) {
+		wg.Add(1)
+		go func(start, end uint64) {
+			defer wg.Done()
+			var first twistededwards.PointAffine
+			first.ScalarMultiplication(&G, new(big.Int).SetUint64(start))
+			var reported uint64
+			walkPoints(&first, &G, end-start, func(off uint64, p *twistededwards.PointAffine) bool {
+				if off%walkBatch == 0 {
+					if ctx.Err() != nil {
+						return false
+					}
+					rep.add(off - reported)
+					reported = off
+				}
+				if _, ok := targets[*p]; ok {
+					mu.Lock()
+					found[*p] = start + off
+					mu.Unlock()
+					if remaining.Add(-1) == 0 {
+						cancel()
+						return false
+					}
+				}
+				return true
+			})
+			if ctx.Err() == nil {
+				rep.add(end - start - reported)
+			}
+		}(c[0], c[1])
+	}
+	wg.Wait()
+	return found
+}
+
+// satMul 饱和乘法，用于估计批量小步表大小
+func satMul(a, b uint64) uint64 {
+	if b != 0 && a > math.MaxUint64/b {
+		return math.MaxUint64
+	}
+	return a * b
+}
@@ -0,0 +1,41 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 区间外的目标只在 Failed 中记为 ErrNotFound，其余目标照常求出
+func TestSolveBatchPartialFailure(t *testing.T) {
+	const lo, hi = 100, 5099
+	G := twistededwards.GetEdwardsCurve().Base
+	ks := []uint64{lo, 2500, 4000, hi}
+	missing := mulBase(hi + 1)
+	targets := []twistededwards.PointAffine{missing}
+	for _, k := range ks {
+		targets = append(targets, mulBase(k))
+	}
+
+	for _, alg := range []Algorithm{Linear, BSGS} {
+		opts := &Options{Algorithm: alg, Threads: 3}
+		res, err := SolveBatch(context.Background(), G, targets, big.NewInt(lo), big.NewInt(hi), nil, opts)
+		if err != nil {
+			t.Fatalf("%v: %v", alg, err)
+		}
+		if len(res.Failed) != 1 || !errors.Is(res.Failed[missing], ErrNotFound) {
+			t.Fatalf("%v: failed = %v, want only the target outside the range with ErrNotFound", alg, res.Failed)
+		}
+		if len(res.Found) != len(ks) {
+			t.Fatalf("%v: found %d targets, want %d", alg, len(res.Found), len(ks))
+		}
+		for i, k := range ks {
+			if got := res.Found[targets[i+1]]; got == nil || got.Uint64() != k {
+				t.Fatalf("%v: target %d: got %v, want %d", alg, i, got, k)
+			}
+		}
+	}
+}
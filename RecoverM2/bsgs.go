@@ -0,0 +1,229 @@
+package recoverm2
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// Table 小步表：j·B（0 ≤ j < m）压缩编码的前 8 字节到 j 的索引
+type Table struct {
+	base   twistededwards.PointAffine
+	m      uint64
+	index  stepIndex
+	closer io.Closer // 表文件的内存映射，内存表为 nil
+}
+
+// Close 释放表文件的内存映射；内存表无需关闭
+func (t *Table) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// Base 返回建表所用的基点
+func (t *Table) Base() twistededwards.PointAffine {
+	return t.base
+}
+
+// BabySteps 返回表中小步数 m
+func (t *Table) BabySteps() uint64 {
+	return t.m
+}
+
+// stepIndex 小步索引：内存哈希表或映射到内存的表文件
+type stepIndex interface {
+	// lookup 返回键为 key 的所有小步（前缀冲突时可能多于一个）
+	lookup(key uint64) []uint32
+}
+
+// babyStep 小步表中的一项
+type babyStep struct {
+	key uint64
+	j   uint32
+}
+
+// mapIndex 内存哈希表索引
+type mapIndex struct {
+	steps map[uint64]uint32
+	extra map[uint64][]uint32 // 前缀冲突时的其余小步
+}
+
+func newMapIndex(entries []babyStep) *mapIndex {
+	idx := &mapIndex{
+		steps: make(map[uint64]uint32, len(entries)),
+		extra: make(map[uint64][]uint32),
+	}
+	for _, e := range entries {
+		if _, ok := idx.steps[e.key]; ok {
+			idx.extra[e.key] = append(idx.extra[e.key], e.j)
+			continue
+		}
+		idx.steps[e.key] = e.j
+	}
+	return idx
+}
+
+func (idx *mapIndex) lookup(key uint64) []uint32 {
+	j, ok := idx.steps[key]
+	if !ok {
+		return nil
+	}
+	return append([]uint32{j}, idx.extra[key]...)
+}
+
+// pointKey 取压缩编码的前 8 字节（y 的低 64 位）作为哈希键
+func pointKey(p *twistededwards.PointAffine) uint64 {
+	return pointKeyBytes(p.Bytes())
+}
+
+// pointKeyBytes 由已计算的压缩编码取键
+func pointKeyBytes(b [32]byte) uint64 {
+	return binary.LittleEndian.Uint64(b[:8])
+}
+
+// BabySteps 按内存上限选择小步数：默认 ⌈√n⌉，不超过 maxEntries
+// 小步越多，大步越少：内存换时间
+func BabySteps(n, maxEntries uint64) uint64 {
+	m := uint64(math.Ceil(math.Sqrt(float64(n))))
+	if m == 0 {
+		m = 1
+	}
+	if maxEntries > 0 && m > maxEntries {
+		m = maxEntries
+	}
+	if m > math.MaxUint32 {
+		m = math.MaxUint32
+	}
+	return m
+}
+
+// NewTable 并行计算 j·B（0 ≤ j < m）并建内存表
+func NewTable(base twistededwards.PointAffine, m uint64, numThreads int) *Table {
+	return &Table{
+		base:  base,
+		m:     m,
+		index: newMapIndex(computeBabySteps(base, m, numThreads)),
+	}
+}
+
+// computeBabySteps 并行计算 j·B（0 ≤ j < m）的键
+func computeBabySteps(base twistededwards.PointAffine, m uint64, numThreads int) []babyStep {
+	chunks := splitRange(0, m, numThreads)
+	parts := make([][]babyStep, len(chunks))
+	var wg sync.WaitGroup
+	for w, c := range chunks {
+		wg.Add(1)
+		go func(w int, start, end uint64) {
+			defer wg.Done()
+			part := make([]babyStep, 0, end-start)
+			var first twistededwards.PointAffine
+			first.ScalarMultiplication(&base, new(big.Int).SetUint64(start))
+			walkPoints(&first, &base, end-start, func(i uint64, p *twistededwards.PointAffine) bool {
+				part = append(part, babyStep{pointKey(p), uint32(start + i)})
+				return true
+			})
+			parts[w] = part
+		}(w, c[0], c[1])
+	}
+	wg.Wait()
+
+	entries := make([]babyStep, 0, m)
+	for _, part := range parts {
+		entries = append(entries, part...)
+	}
+	return entries
+}
+
+// Lookup 查表求 j 使 j·B = p（0 ≤ j < m），不在表中时返回 false
+func (t *Table) Lookup(p twistededwards.PointAffine) (uint64, bool) {
+	for _, j := range t.lookup(&p) {
+		if t.check(&p, uint64(j)) {
+			return uint64(j), true
+		}
+	}
+	return 0, false
+}
+
+// lookup 返回与 p 前缀相同的所有小步
+func (t *Table) lookup(p *twistededwards.PointAffine) []uint32 {
+	return t.index.lookup(pointKey(p))
+}
+
+// plan 在 [0, maxVal] 内求 k 使 k·B = P：大步 Q_i = P − i·m·B，查表命中 j 则 k = i·m + j。
+// 每个单位是一个大步，单位区间为 [0, ⌊maxVal/m⌋ + 1)。
+func (t *Table) plan(P twistededwards.PointAffine, maxVal uint64) *searchPlan {
+	// −m·B
+	var giantStep twistededwards.PointAffine
+	giantStep.ScalarMultiplication(&t.base, new(big.Int).SetUint64(t.m))
+	giantStep.Neg(&giantStep)
+
+	scan := func(ctx context.Context, start, end uint64, rep *progressReporter) (uint64, bool) {
+		var Q twistededwards.PointAffine
+		Q.ScalarMultiplication(&giantStep, new(big.Int).SetUint64(start))
+		Q.Add(&Q, &P)
+		var found, reported uint64
+		var ok bool
+		walkPoints(&Q, &giantStep, end-start, func(off uint64, Q *twistededwards.PointAffine) bool {
+			i := start + off
+			if off%4096 == 0 {
+				if ctx.Err() != nil {
+					return false
+				}
+				rep.add((off - reported) * t.m)
+				reported = off
+			}
+			for _, j := range t.lookup(Q) {
+				k := i*t.m + uint64(j)
+				if k <= maxVal && t.check(&P, k) {
+					found, ok = k, true
+					return false
+				}
+			}
+			return true
+		})
+		if !ok && ctx.Err() == nil {
+			rep.add((end - start - reported) * t.m)
+		}
+		return found, ok
+	}
+	return &searchPlan{units: maxVal/t.m + 1, step: t.m, scan: scan}
+}
+
+// check 验证 k·B = P，排除前缀碰撞
+func (t *Table) check(P *twistededwards.PointAffine, k uint64) bool {
+	var candidate twistededwards.PointAffine
+	candidate.ScalarMultiplication(&t.base, new(big.Int).SetUint64(k))
+	return candidate.Equal(P)
+}
+
+// splitRange 将 [start, end) 尽量均匀地分成至多 parts 段
+func splitRange(start, end uint64, parts int) [][2]uint64 {
+	if parts < 1 {
+		parts = 1
+	}
+	n := end - start
+	if n < uint64(parts) {
+		parts = int(n)
+		if parts == 0 {
+			return nil
+		}
+	}
+	res := make([][2]uint64, 0, parts)
+	chunk := n / uint64(parts)
+	for i := 0; i < parts; i++ {
+		s := start + uint64(i)*chunk
+		e := s + chunk
+		if i == parts-1 {
+			e = end
+		}
+		res = append(res, [2]uint64{s, e})
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// testTables 返回 m 项的内存表与映射到内存的表文件
+func testTables(t *testing.T, m uint64) map[string]*Table {
+	t.Helper()
+	base := twistededwards.GetEdwardsCurve().Base
+	path := filepath.Join(t.TempDir(), TableFileName(&base))
+	if err := WriteTable(path, base, m, 2); err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := OpenTable(path, base, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mapped.Close() })
+	return map[string]*Table{"memory": NewTable(base, m, 2), "mmap": mapped}
+}
+
+func solveBSGS(table *Table, target twistededwards.PointAffine, lo, hi uint64) (*big.Int, error) {
+	G := twistededwards.GetEdwardsCurve().Base
+	opts := &Options{Algorithm: BSGS, Threads: 2, Table: table}
+	return Solve(context.Background(), G, target, new(big.Int).SetUint64(lo), new(big.Int).SetUint64(hi), nil, opts)
+}
+
+func TestBSGSSolve(t *testing.T) {
+	for name, table := range testTables(t, 100) {
+		for _, k := range []uint64{0, 1, 99, 100, 5000, 9999} {
+			if got, err := solveBSGS(table, mulBase(k), 0, 9999); err != nil || got.Uint64() != k {
+				t.Fatalf("%s: got (%v, %v), want %d", name, got, err, k)
+			}
+		}
+		if _, err := solveBSGS(table, mulBase(10000), 0, 9999); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s: above hi: got %v, want ErrNotFound", name, err)
+		}
+	}
+
+	// 不传表时按区间建内存表
+	if got, err := solveBSGS(nil, mulBase(4321), 0, 9999); err != nil || got.Uint64() != 4321 {
+		t.Fatalf("default table: got (%v, %v), want 4321", got, err)
+	}
+}
+
+// lo > 0 时在 [0, hi − lo] 内求解平移后的目标，结果再加回 lo
+func TestBSGSOffset(t *testing.T) {
+	const lo, hi = 1000, 1999
+	for name, table := range testTables(t, 32) {
+		for _, k := range []uint64{lo, 1500, hi} {
+			if got, err := solveBSGS(table, mulBase(k), lo, hi); err != nil || got.Uint64() != k {
+				t.Fatalf("%s: got (%v, %v), want %d", name, got, err, k)
+			}
+		}
+		for _, k := range []uint64{lo - 1, hi + 1} {
+			if _, err := solveBSGS(table, mulBase(k), lo, hi); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("%s: k = %d outside [lo, hi]: got %v, want ErrNotFound", name, k, err)
+			}
+		}
+	}
+}
+
+// 最后一个大步覆盖 [90, 99]，超过 hi = 95 的部分即使命中也不能返回
+func TestBSGSLastGiantStep(t *testing.T) {
+	const hi = 95
+	for name, table := range testTables(t, 10) {
+		if got, err := solveBSGS(table, mulBase(hi), 0, hi); err != nil || got.Uint64() != hi {
+			t.Fatalf("%s: got (%v, %v), want %d", name, got, err, hi)
+		}
+		for _, k := range []uint64{hi + 1, 99} {
+			if got, err := solveBSGS(table, mulBase(k), 0, hi); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("%s: k = %d past hi: got (%v, %v), want ErrNotFound", name, k, got, err)
+			}
+		}
+	}
+}
+
+func TestOpenTableChecksum(t *testing.T) {
+	base := twistededwards.GetEdwardsCurve().Base
+	path := filepath.Join(t.TempDir(), TableFileName(&base))
+	if err := WriteTable(path, base, 64, 1); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[tableHeaderSize+8] ^= 1 // 第一项的 j
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenTable(path, base, true); !errors.Is(err, ErrTableChecksum) {
+		t.Fatalf("got %v, want ErrTableChecksum", err)
+	}
+	// 不校验时可以打开，查表仍由 check 排除错误的小步
+	table, err := OpenTable(path, base, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Close()
+
+	var other twistededwards.PointAffine
+	other.Double(&base)
+	if _, err := OpenTable(path, other, false); !errors.Is(err, ErrTableBase) {
+		t.Fatalf("other base: got %v, want ErrTableBase", err)
+	}
+}
@@ -0,0 +1,184 @@
+package recoverm2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+const (
+	// checkpointVersion 检查点与协作文件格式版本
+	checkpointVersion = 1
+	// checkpointChunk 每个 worker 完成这么多单位后才推进已完成位置，约 1 秒的工作量
+	checkpointChunk = 1 << 20
+	// defaultCheckpointInterval 默认检查点写入间隔
+	defaultCheckpointInterval = 30 * time.Second
+)
+
+var (
+	ErrCheckpointMismatch = errors.New("recoverm2: checkpoint belongs to a different search")
+	ErrCheckpointFormat   = errors.New("recoverm2: malformed checkpoint file")
+
+	errCheckpointAlgorithm = errors.New("recoverm2: checkpoints require the Linear or BSGS algorithm")
+	errCheckpointOptions   = errors.New("recoverm2: Checkpoint and Coordinator are mutually exclusive")
+)
+
+// jobID 标识一次搜索，检查点与协作文件只能用于同一问题。
+// 小步大步法的单位是大步，Step（即 m）不同的表不能续传。
+type jobID struct {
+	Base      string    `json:"base"`
+	Target    string    `json:"target"`
+	Lo        string    `json:"lo"`
+	Hi        string    `json:"hi"`
+	Algorithm Algorithm `json:"algorithm"`
+	Step      uint64    `json:"step"`
+	Units     uint64    `json:"units"`
+}
+
+func newJobID(base, target twistededwards.PointAffine, lo, hi *big.Int, algo Algorithm, sp *searchPlan) jobID {
+	b, t := base.Bytes(), target.Bytes()
+	return jobID{
+		Base:      hex.EncodeToString(b[:]),
+		Target:    hex.EncodeToString(t[:]),
+		Lo:        lo.String(),
+		Hi:        hi.String(),
+		Algorithm: algo,
+		Step:      sp.step,
+		Units:     sp.units,
+	}
+}
+
+// workerRange 一个 worker 的单位区间 [Start, End)，[Start, Next) 已搜索完
+type workerRange struct {
+	Start uint64 `json:"start"`
+	Next  uint64 `json:"next"`
+	End   uint64 `json:"end"`
+}
+
+// checkpointState 检查点文件内容（JSON）
+type checkpointState struct {
+	Version int           `json:"version"`
+	Job     jobID         `json:"job"`
+	Workers []workerRange `json:"workers"`
+	Found   *uint64       `json:"found,omitempty"` // 相对 lo 的解
+}
+
+// readJSONFile 读取 JSON 状态文件；文件不存在时返回 os.ErrNotExist
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrCheckpointFormat
+	}
+	return nil
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// solveCheckpointed 与 solve 相同，但每个 worker 的已完成位置定期写入检查点文件；
+// 文件已存在时从中记录的位置继续。取消时也会写入最新进度。
+func (sp *searchPlan) solveCheckpointed(ctx context.Context, path string, id jobID, numThreads int, interval time.Duration, rep *progressReporter) (uint64, bool, error) {
+	var state checkpointState
+	err := readJSONFile(path, &state)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		state = checkpointState{Version: checkpointVersion, Job: id}
+		for _, c := range splitRange(0, sp.units, numThreads) {
+			state.Workers = append(state.Workers, workerRange{c[0], c[0], c[1]})
+		}
+	case err != nil:
+		return 0, false, err
+	case state.Version != checkpointVersion:
+		return 0, false, ErrCheckpointFormat
+	case state.Job != id:
+		return 0, false, ErrCheckpointMismatch
+	}
+	if state.Found != nil {
+		return *state.Found, true, nil
+	}
+	for _, w := range state.Workers {
+		if w.Start > w.Next || w.Next > w.End || w.End > sp.units {
+			return 0, false, ErrCheckpointFormat
+		}
+		rep.add((w.Next - w.Start) * sp.step)
+	}
+
+	next := make([]atomic.Uint64, len(state.Workers))
+	for i, w := range state.Workers {
+		next[i].Store(w.Next)
+	}
+	// snapshot 在 mu 保护下读取各 worker 位置并写文件，避免定期写入与最终写入交错
+	var mu sync.Mutex
+	snapshot := func(found *uint64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := range state.Workers {
+			state.Workers[i].Next = next[i].Load()
+		}
+		state.Found = found
+		return writeJSONFile(path, &state)
+	}
+
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				snapshot(nil) // 失败时等下一次写入，最终写入的错误会返回给调用者
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	k, ok := firstResult(ctx, len(state.Workers), func(ctx context.Context, w int) (uint64, bool) {
+		end := state.Workers[w].End
+		for pos := next[w].Load(); pos < end; {
+			e := min(pos+checkpointChunk, end)
+			if k, ok := sp.scan(ctx, pos, e, rep); ok {
+				return k, true
+			}
+			if ctx.Err() != nil {
+				return 0, false
+			}
+			next[w].Store(e)
+			pos = e
+		}
+		return 0, false
+	})
+	close(stop)
+	wg.Wait()
+
+	var found *uint64
+	if ok {
+		found = &k
+	}
+	if err := snapshot(found); err != nil {
+		return k, ok, err
+	}
+	return k, ok, nil
+}
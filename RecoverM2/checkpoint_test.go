@@ -0,0 +1,100 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// mulBase 返回 k·G
+func mulBase(k uint64) twistededwards.PointAffine {
+	G := twistededwards.GetEdwardsCurve().Base
+	var P twistededwards.PointAffine
+	P.ScalarMultiplication(&G, new(big.Int).SetUint64(k))
+	return P
+}
+
+// writeCheckpoint 为 [0, hi] 内对 target 的线性搜索写入检查点，workers 给出各 worker 的进度
+func writeCheckpoint(t *testing.T, path string, target twistededwards.PointAffine, hi uint64, workers []workerRange) {
+	t.Helper()
+	G := twistededwards.GetEdwardsCurve().Base
+	id := newJobID(G, target, big.NewInt(0), new(big.Int).SetUint64(hi), Linear, linearPlan(G, target, hi))
+	if err := writeJSONFile(path, &checkpointState{Version: checkpointVersion, Job: id, Workers: workers}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func solveCheckpoint(path string, target twistededwards.PointAffine, hi uint64) (*big.Int, error) {
+	G := twistededwards.GetEdwardsCurve().Base
+	opts := &Options{Algorithm: Linear, Threads: 2, Checkpoint: path}
+	return Solve(context.Background(), G, target, big.NewInt(0), new(big.Int).SetUint64(hi), nil, opts)
+}
+
+// 续传时跳过检查点中已完成的子区间：解在未完成部分时仍能找到，在已完成部分时不再重复搜索
+func TestCheckpointResume(t *testing.T) {
+	const hi = 9999
+	workers := []workerRange{{0, 5000, 5000}, {5000, 5000, 10000}}
+
+	path := filepath.Join(t.TempDir(), "resume.json")
+	target := mulBase(7000)
+	writeCheckpoint(t, path, target, hi, workers)
+	k, err := solveCheckpoint(path, target, hi)
+	if err != nil || k.Uint64() != 7000 {
+		t.Fatalf("got (%v, %v), want 7000", k, err)
+	}
+	var state checkpointState
+	if err := readJSONFile(path, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Found == nil || *state.Found != 7000 {
+		t.Fatalf("checkpoint records found = %v, want 7000", state.Found)
+	}
+	// 已记录解的检查点直接返回
+	if k, err := solveCheckpoint(path, target, hi); err != nil || k.Uint64() != 7000 {
+		t.Fatalf("rerun: got (%v, %v), want 7000", k, err)
+	}
+
+	path = filepath.Join(t.TempDir(), "skip.json")
+	target = mulBase(3000)
+	writeCheckpoint(t, path, target, hi, workers)
+	if _, err := solveCheckpoint(path, target, hi); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("solution in a finished sub-range: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestCheckpointRejected(t *testing.T) {
+	const hi = 999
+	dir := t.TempDir()
+	target := mulBase(10)
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := solveCheckpoint(corrupt, target, hi); !errors.Is(err, ErrCheckpointFormat) {
+		t.Fatalf("corrupt: got %v, want ErrCheckpointFormat", err)
+	}
+
+	badRange := filepath.Join(dir, "range.json")
+	writeCheckpoint(t, badRange, target, hi, []workerRange{{0, 600, 500}, {500, 500, 1000}})
+	if _, err := solveCheckpoint(badRange, target, hi); !errors.Is(err, ErrCheckpointFormat) {
+		t.Fatalf("next past end: got %v, want ErrCheckpointFormat", err)
+	}
+
+	// 为目标 10·G、区间 [0, 999] 写下的检查点不能用于别的目标或区间
+	path := filepath.Join(dir, "job.json")
+	if k, err := solveCheckpoint(path, target, hi); err != nil || k.Uint64() != 10 {
+		t.Fatalf("got (%v, %v), want 10", k, err)
+	}
+	if _, err := solveCheckpoint(path, mulBase(11), hi); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Fatalf("other target: got %v, want ErrCheckpointMismatch", err)
+	}
+	if _, err := solveCheckpoint(path, target, hi+1); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Fatalf("other range: got %v, want ErrCheckpointMismatch", err)
+	}
+}
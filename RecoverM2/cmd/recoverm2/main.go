@@ -0,0 +1,260 @@
+// recoverm2 在区间 [lo, hi] 内求解 k·B = P，结果以 JSON 输出到标准输出。
+//
+// 退出码：
+//
+//	0  所有目标都已求出
+//	1  至少一个目标不在区间内
+//	2  参数错误
+//	3  超时或被中断（Ctrl-C），已设置检查点时进度已保存
+//	4  其他错误（如表文件或检查点文件读写失败）
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"MissionYang/ConfAmount"
+	recoverm2 "MissionYang/RecoverM2"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+const (
+	exitOK       = 0
+	exitNotFound = 1
+	exitUsage    = 2
+	exitCanceled = 3
+	exitError    = 4
+)
+
+var (
+	targetFlag  = flag.String("target", "", "目标点的压缩编码（十六进制），多个目标用逗号分隔")
+	baseFlag    = flag.String("base", "G", "基点：G、h（金额基点）或压缩点的十六进制编码")
+	loFlag      = flag.String("lo", "0", "区间下界（十进制）")
+	hiFlag      = flag.String("hi", "100000000", "区间上界（十进制，含）")
+	algoFlag    = flag.String("algo", "linear", "算法：linear、bsgs 或 kangaroo")
+	threads     = flag.Int("threads", runtime.NumCPU(), "并行线程数")
+	tableFlag   = flag.String("table", "", "小步表文件（bsgs）；-gen-table 时为输出路径，默认按基点命名")
+	genTable    = flag.Bool("gen-table", false, "生成小步表文件后退出")
+	babySteps   = flag.Uint64("baby-steps", 1<<24, "小步表项数上限（内存换时间）")
+	skipVerify  = flag.Bool("skip-table-verify", false, "加载小步表文件时跳过 sha256 校验（默认校验）")
+	timeout     = flag.Duration("timeout", 0, "超时时间，0 表示不限")
+	checkpoint  = flag.String("checkpoint", "", "检查点文件（linear、bsgs），已存在时从中继续")
+	coordinator = flag.String("coordinator", "", "协作文件（linear、bsgs），多个进程使用同一文件分摊工作")
+	progress    = flag.Bool("progress", false, "向标准错误输出进度")
+)
+
+// output 标准输出的 JSON 结果
+type output struct {
+	Base      string   `json:"base"`
+	Algorithm string   `json:"algorithm"`
+	Lo        string   `json:"lo"`
+	Hi        string   `json:"hi"`
+	ElapsedMs int64    `json:"elapsed_ms"`
+	Results   []result `json:"results"`
+	Error     string   `json:"error,omitempty"` // 参数错误等使整个调用失败的错误
+}
+
+// result 单个目标的结果
+type result struct {
+	Target string `json:"target"`
+	Found  bool   `json:"found"`
+	K      string `json:"k,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func main() {
+	flag.Parse()
+	os.Exit(run())
+}
+
+func run() int {
+	// 解析前先填入原始参数，失败时输出的对象字段与成功时相同
+	out := &output{
+		Base:      *baseFlag,
+		Algorithm: *algoFlag,
+		Lo:        *loFlag,
+		Hi:        *hiFlag,
+		Results:   []result{},
+	}
+	base, err := parseBasePoint(*baseFlag)
+	if err != nil {
+		return out.fail(exitUsage, fmt.Errorf("invalid -base: %w", err))
+	}
+	baseBytes := base.Bytes()
+	out.Base = hex.EncodeToString(baseBytes[:])
+
+	// 生成小步表文件
+	if *genTable {
+		path := *tableFlag
+		if path == "" {
+			path = recoverm2.TableFileName(&base)
+		}
+		if err := recoverm2.WriteTable(path, base, *babySteps, *threads); err != nil {
+			return out.fail(exitError, err)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d baby steps to %s\n", *babySteps, filepath.Clean(path))
+		return exitOK
+	}
+
+	targets, err := parseTargets(*targetFlag)
+	if err != nil {
+		return out.fail(exitUsage, fmt.Errorf("invalid -target: %w", err))
+	}
+	lo, ok1 := new(big.Int).SetString(*loFlag, 10)
+	hi, ok2 := new(big.Int).SetString(*hiFlag, 10)
+	if !ok1 || !ok2 {
+		return out.fail(exitUsage, errors.New("invalid -lo or -hi"))
+	}
+	out.Lo, out.Hi = lo.String(), hi.String()
+	algo, err := recoverm2.ParseAlgorithm(*algoFlag)
+	if err != nil {
+		return out.fail(exitUsage, err)
+	}
+	out.Algorithm = algo.String()
+	if len(targets) > 1 && (*checkpoint != "" || *coordinator != "") {
+		return out.fail(exitUsage, errors.New("-checkpoint and -coordinator take a single -target"))
+	}
+
+	opts := &recoverm2.Options{
+		Algorithm:   algo,
+		Threads:     *threads,
+		BabySteps:   *babySteps,
+		Checkpoint:  *checkpoint,
+		Coordinator: *coordinator,
+	}
+	if algo == recoverm2.BSGS && *tableFlag != "" {
+		table, err := recoverm2.OpenTable(*tableFlag, base, !*skipVerify)
+		if err != nil {
+			return out.fail(exitError, err)
+		}
+		defer table.Close()
+		opts.Table = table
+	}
+
+	// Ctrl-C 与超时都通过 ctx 取消，检查点在返回前写入
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	var progressFn recoverm2.ProgressFunc
+	if *progress {
+		progressFn = func(done, total uint64) {
+			fmt.Fprintf(os.Stderr, "progress: %d / %d\n", done, total)
+		}
+	}
+
+	start := time.Now()
+	code := exitOK
+	if len(targets) == 1 {
+		k, err := recoverm2.Solve(ctx, base, targets[0], lo, hi, progressFn, opts)
+		out.Results = append(out.Results, newResult(targets[0], k, err))
+		code = exitCode(err)
+	} else {
+		batch, err := recoverm2.SolveBatch(ctx, base, targets, lo, hi, progressFn, opts)
+		if err != nil {
+			out.ElapsedMs = time.Since(start).Milliseconds()
+			return out.fail(exitCode(err), err)
+		}
+		for _, target := range targets {
+			res := newResult(target, batch.Found[target], batch.Failed[target])
+			out.Results = append(out.Results, res)
+			code = max(code, exitCode(batch.Failed[target]))
+		}
+	}
+	out.ElapsedMs = time.Since(start).Milliseconds()
+	return out.write(code)
+}
+
+// exitCode 将求解错误映射为退出码
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, recoverm2.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return exitCanceled
+	case errors.Is(err, recoverm2.ErrInvalidRange), errors.Is(err, recoverm2.ErrRangeTooWide):
+		return exitUsage
+	}
+	return exitError
+}
+
+func newResult(target twistededwards.PointAffine, k *big.Int, err error) result {
+	b := target.Bytes()
+	res := result{Target: hex.EncodeToString(b[:])}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Found = true
+	res.K = k.String()
+	return res
+}
+
+// write 将结果以 JSON 输出到标准输出并返回退出码，输出失败时返回 exitError
+func (out *output) write(code int) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return exitError
+	}
+	return code
+}
+
+// fail 在 error 字段中记录错误，输出与成功时同样的对象并返回退出码
+func (out *output) fail(code int, err error) int {
+	out.Error = err.Error()
+	return out.write(code)
+}
+
+// parseBasePoint 解析基点：G、h 或压缩点的十六进制编码
+func parseBasePoint(s string) (twistededwards.PointAffine, error) {
+	switch s {
+	case "G":
+		return twistededwards.GetEdwardsCurve().Base, nil
+	case "h":
+		return confamount.DefaultParams().H, nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return twistededwards.PointAffine{}, err
+	}
+	return curveutil.PointFromBytes(b)
+}
+
+// parseTargets 解析逗号分隔的目标点
+func parseTargets(s string) ([]twistededwards.PointAffine, error) {
+	if s == "" {
+		return nil, errors.New("missing target point")
+	}
+	var targets []twistededwards.PointAffine
+	for _, part := range strings.Split(s, ",") {
+		b, err := hex.DecodeString(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		p, err := curveutil.PointFromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, p)
+	}
+	return targets, nil
+}
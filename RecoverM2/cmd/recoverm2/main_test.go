@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 设置 RECOVERM2_RUN_MAIN 时测试程序本身作为 recoverm2 运行，参数取自命令行
+func TestMain(m *testing.M) {
+	if os.Getenv("RECOVERM2_RUN_MAIN") == "1" {
+		main()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runMain 以 args 运行 recoverm2，返回退出码与标准输出中的 JSON 对象
+func runMain(t *testing.T, args ...string) (int, map[string]json.RawMessage) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "RECOVERM2_RUN_MAIN=1")
+	stdout, err := cmd.Output()
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		t.Fatalf("%v: stdout is not a JSON object: %v\n%s", args, err, stdout)
+	}
+	return code, out
+}
+
+func targetHex(k int64) string {
+	G := twistededwards.GetEdwardsCurve().Base
+	var P twistededwards.PointAffine
+	P.ScalarMultiplication(&G, big.NewInt(k))
+	b := P.Bytes()
+	return hex.EncodeToString(b[:])
+}
+
+// 每种退出码下输出的顶层对象字段相同，整个调用失败时另有 error 字段
+func TestExitCodes(t *testing.T) {
+	missingTable := filepath.Join(t.TempDir(), "missing.tbl")
+	cases := []struct {
+		name     string
+		args     []string
+		code     int
+		topError bool
+	}{
+		{"found", []string{"-target", targetHex(42), "-hi", "1000"}, exitOK, false},
+		{"not found", []string{"-target", targetHex(2000), "-hi", "1000"}, exitNotFound, false},
+		{"batch not found", []string{"-target", targetHex(42) + "," + targetHex(2000), "-hi", "1000"}, exitNotFound, false},
+		{"bad range", []string{"-target", targetHex(42), "-lo", "x"}, exitUsage, true},
+		{"bad target", []string{"-target", "zz"}, exitUsage, true},
+		{"bad algorithm", []string{"-target", targetHex(42), "-algo", "rho"}, exitUsage, true},
+		{"timeout", []string{"-target", targetHex(3), "-lo", "10", "-hi", "1099511627775", "-threads", "1", "-timeout", "50ms"}, exitCanceled, false},
+		{"missing table", []string{"-target", targetHex(42), "-algo", "bsgs", "-table", missingTable}, exitError, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, out := runMain(t, c.args...)
+			if code != c.code {
+				t.Fatalf("exit code %d, want %d", code, c.code)
+			}
+			for _, key := range []string{"base", "algorithm", "lo", "hi", "elapsed_ms", "results"} {
+				if _, ok := out[key]; !ok {
+					t.Fatalf("output lacks %q: %v", key, out)
+				}
+			}
+			if _, ok := out["error"]; ok != c.topError {
+				t.Fatalf("top-level error present = %v, want %v", ok, c.topError)
+			}
+			var results []result
+			if err := json.Unmarshal(out["results"], &results); err != nil {
+				t.Fatal(err)
+			}
+			// 各用例的最后一个目标决定退出码
+			if !c.topError && (len(results) == 0 || results[len(results)-1].Found != (c.code == exitOK)) {
+				t.Fatalf("results = %+v", results)
+			}
+		})
+	}
+}
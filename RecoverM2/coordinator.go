@@ -0,0 +1,236 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// 以下参数为变量只是为了让测试能调小
+var (
+	// coordUnitSize 每个工作单元包含的单位数，单核约 45 秒
+	coordUnitSize uint64 = 1 << 26
+	// coordLease 工作单元的租约时长，扫描期间每 coordLease/3 续约一次；
+	// 进程退出后其单元在租约到期后由其他进程接手
+	coordLease = 10 * time.Minute
+	// coordPoll 没有可领取的单元、但仍有单元在其他进程手中时的轮询间隔
+	coordPoll = time.Second
+)
+
+// lease 已分配但尚未完成的工作单元
+type lease struct {
+	Unit    uint64    `json:"unit"`
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// coordState 协作文件内容（JSON）：单元 [0, Next) 中不在 Leases 里的都已完成
+type coordState struct {
+	Version  int     `json:"version"`
+	Job      jobID   `json:"job"`
+	UnitSize uint64  `json:"unit_size"`
+	Next     uint64  `json:"next"`
+	Leases   []lease `json:"leases"`
+	Found    *uint64 `json:"found,omitempty"` // 相对 lo 的解
+}
+
+// claimResult 领取工作单元的结果
+type claimResult int
+
+const (
+	claimUnit  claimResult = iota // 领到一个单元
+	claimFound                    // 已有进程找到解
+	claimWait                     // 暂无可领单元，其余单元仍在其他进程手中
+	claimDone                     // 所有单元都已完成且未找到
+)
+
+// coordinator 通过加锁读写同一个协作文件，把工作单元分给同一台机器（或共享支持 flock 的文件系统）上的多个进程
+type coordinator struct {
+	path  string
+	id    jobID
+	owner string
+	units uint64 // 工作单元总数
+}
+
+func newCoordinator(path string, id jobID) *coordinator {
+	host, _ := os.Hostname()
+	return &coordinator{
+		path:  path,
+		id:    id,
+		owner: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		units: (id.Units + coordUnitSize - 1) / coordUnitSize,
+	}
+}
+
+// update 在文件锁内读出状态、调用 fn 修改并写回；文件不存在时从空状态开始
+func (c *coordinator) update(fn func(st *coordState) error) error {
+	unlock, err := lockFile(c.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var st coordState
+	err = readJSONFile(c.path, &st)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		st = coordState{Version: checkpointVersion, Job: c.id, UnitSize: coordUnitSize}
+	case err != nil:
+		return err
+	case st.Version != checkpointVersion || st.UnitSize != coordUnitSize:
+		return ErrCheckpointFormat
+	case st.Job != c.id:
+		return ErrCheckpointMismatch
+	}
+	if err := fn(&st); err != nil {
+		return err
+	}
+	return writeJSONFile(c.path, &st)
+}
+
+// claim 领取一个工作单元：优先接手租约已过期的单元，其次分配新单元
+func (c *coordinator) claim() (res claimResult, unit, found uint64, err error) {
+	err = c.update(func(st *coordState) error {
+		if st.Found != nil {
+			res, found = claimFound, *st.Found
+			return nil
+		}
+		now := time.Now()
+		for i := range st.Leases {
+			if now.After(st.Leases[i].Expires) {
+				st.Leases[i].Owner = c.owner
+				st.Leases[i].Expires = now.Add(coordLease)
+				res, unit = claimUnit, st.Leases[i].Unit
+				return nil
+			}
+		}
+		if st.Next < c.units {
+			unit = st.Next
+			st.Next++
+			st.Leases = append(st.Leases, lease{unit, c.owner, now.Add(coordLease)})
+			res = claimUnit
+			return nil
+		}
+		if len(st.Leases) > 0 {
+			res = claimWait
+		} else {
+			res = claimDone
+		}
+		return nil
+	})
+	return res, unit, found, err
+}
+
+// complete 归还工作单元：done 为真表示已搜索完，否则（被取消）立即让其他进程接手
+func (c *coordinator) complete(unit uint64, done bool, k uint64, ok bool) error {
+	return c.update(func(st *coordState) error {
+		for i := range st.Leases {
+			if st.Leases[i].Unit != unit {
+				continue
+			}
+			if done || ok {
+				st.Leases = append(st.Leases[:i], st.Leases[i+1:]...)
+			} else {
+				st.Leases[i].Expires = time.Time{}
+			}
+			break
+		}
+		if ok && st.Found == nil {
+			st.Found = &k
+		}
+		return nil
+	})
+}
+
+// renew 延长本进程持有的单元租约，扫描时间超过 coordLease 的单元不会被其他进程重复领取
+func (c *coordinator) renew(unit uint64) error {
+	return c.update(func(st *coordState) error {
+		for i := range st.Leases {
+			if st.Leases[i].Unit == unit && st.Leases[i].Owner == c.owner {
+				st.Leases[i].Expires = time.Now().Add(coordLease)
+			}
+		}
+		return nil
+	})
+}
+
+// solveShared 与其他进程协作求解：反复从协作文件领取工作单元，用全部线程扫描后归还。
+// 协作文件同时也是检查点，进程重启后用同一文件即可继续，中断进程持有的单元在租约到期后重新分配。
+func (sp *searchPlan) solveShared(ctx context.Context, path string, id jobID, numThreads int, rep *progressReporter) (uint64, bool, error) {
+	c := newCoordinator(path, id)
+	for {
+		res, unit, found, err := c.claim()
+		if err != nil {
+			return 0, false, err
+		}
+		switch res {
+		case claimFound:
+			return found, true, nil
+		case claimDone:
+			return 0, false, nil
+		case claimWait:
+			select {
+			case <-ctx.Done():
+				return 0, false, nil
+			case <-time.After(coordPoll):
+			}
+			continue
+		}
+
+		k, ok, done := c.scanUnit(ctx, sp, unit, numThreads, rep)
+		if err := c.complete(unit, done, k, ok); err != nil {
+			return k, ok, err
+		}
+		if ok {
+			return k, true, nil
+		}
+		if ctx.Err() != nil {
+			return 0, false, nil
+		}
+	}
+}
+
+// scanUnit 用全部线程扫描一个工作单元；期间定期查看协作文件，其他进程找到解后提前结束，并定期为单元续约。
+// done 表示单元已完整扫描（或已有解），可以从租约中移除。
+func (c *coordinator) scanUnit(ctx context.Context, sp *searchPlan, unit uint64, numThreads int, rep *progressReporter) (k uint64, ok, done bool) {
+	unitCtx, cancel := context.WithCancel(ctx)
+	// 返回前等轮询 goroutine 退出，之后的 complete 不会与续约交错
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(coordPoll)
+		defer ticker.Stop()
+		renew := time.NewTicker(coordLease / 3)
+		defer renew.Stop()
+		for {
+			select {
+			case <-unitCtx.Done():
+				return
+			case <-renew.C:
+				c.renew(unit) // 失败时等下一次续约
+			case <-ticker.C:
+				var st coordState
+				if readJSONFile(c.path, &st) == nil && st.Found != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	start := unit * coordUnitSize
+	end := min(start+coordUnitSize, sp.units)
+	chunks := splitRange(start, end, numThreads)
+	k, ok = firstResult(unitCtx, len(chunks), func(ctx context.Context, w int) (uint64, bool) {
+		return sp.scan(ctx, chunks[w][0], chunks[w][1], rep)
+	})
+	return k, ok, ok || unitCtx.Err() == nil || ctx.Err() == nil
+}
@@ -0,0 +1,176 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// smallUnits 把工作单元调小到 4096 个候选、轮询间隔调到 10ms，测试结束后恢复
+func smallUnits(t *testing.T) {
+	unitSize, lease, poll := coordUnitSize, coordLease, coordPoll
+	coordUnitSize, coordPoll = 1<<12, 10*time.Millisecond
+	t.Cleanup(func() {
+		coordUnitSize, coordLease, coordPoll = unitSize, lease, poll
+	})
+}
+
+// coordJob 返回 [0, hi] 内对 target 线性搜索的 jobID
+func coordJob(target twistededwards.PointAffine, hi uint64) jobID {
+	G := twistededwards.GetEdwardsCurve().Base
+	return newJobID(G, target, big.NewInt(0), new(big.Int).SetUint64(hi), Linear, linearPlan(G, target, hi))
+}
+
+func solveShared(ctx context.Context, path string, target twistededwards.PointAffine, hi uint64, progress ProgressFunc) (*big.Int, error) {
+	G := twistededwards.GetEdwardsCurve().Base
+	opts := &Options{Algorithm: Linear, Threads: 1, Coordinator: path}
+	return Solve(ctx, G, target, big.NewInt(0), new(big.Int).SetUint64(hi), progress, opts)
+}
+
+// 两次 Solve 共用一个协作文件：各自领取不同的单元，一方找到解后双方都返回该解
+func TestCoordinatorSplitsWork(t *testing.T) {
+	smallUnits(t)
+	const hi = 16<<12 - 1
+	const k = 15<<12 + 100
+	path := filepath.Join(t.TempDir(), "coord.json")
+	target := mulBase(k)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	results := make([]*big.Int, 2)
+	errs := make([]error, 2)
+	done := make([]uint64, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = solveShared(ctx, path, target, hi, func(d, _ uint64) { done[i] = d })
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i].Uint64() != k {
+			t.Fatalf("solver %d: got (%v, %v), want %d", i, results[i], errs[i], k)
+		}
+	}
+	// 单元不重复扫描：两者的工作量之和不超过区间大小，且都分到了单元
+	if done[0] == 0 || done[1] == 0 || done[0]+done[1] > hi+1 {
+		t.Fatalf("work split %d + %d over %d candidates", done[0], done[1], hi+1)
+	}
+	var st coordState
+	if err := readJSONFile(path, &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Found == nil || *st.Found != k || len(st.Leases) != 0 {
+		t.Fatalf("coordinator file: found %v, leases %v", st.Found, st.Leases)
+	}
+}
+
+// 其他进程持有的单元在租约到期前不会被领取，到期后由本进程接手
+func TestCoordinatorLeaseExpiry(t *testing.T) {
+	smallUnits(t)
+	const hi = 2<<12 - 1
+	const k = 100 // 位于单元 0
+	path := filepath.Join(t.TempDir(), "coord.json")
+	target := mulBase(k)
+	c := newCoordinator(path, coordJob(target, hi))
+
+	// 单元 1 已完成，单元 0 由已退出的进程持有
+	hold := func(expires time.Time) {
+		if err := c.update(func(st *coordState) error {
+			st.Next = 2
+			st.Leases = []lease{{Unit: 0, Owner: "dead:1", Expires: expires}}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hold(time.Now().Add(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := solveShared(ctx, path, target, hi, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("live lease: got %v, want context.DeadlineExceeded", err)
+	}
+
+	hold(time.Now().Add(-time.Second))
+	if got, err := solveShared(context.Background(), path, target, hi, nil); err != nil || got.Uint64() != k {
+		t.Fatalf("lapsed lease: got (%v, %v), want %d", got, err, k)
+	}
+}
+
+// 续约只延长本进程持有的租约
+func TestCoordinatorRenew(t *testing.T) {
+	smallUnits(t)
+	const hi = 2<<12 - 1
+	path := filepath.Join(t.TempDir(), "coord.json")
+	c := newCoordinator(path, coordJob(mulBase(1), hi))
+	if res, unit, _, err := c.claim(); err != nil || res != claimUnit || unit != 0 {
+		t.Fatalf("claim: (%v, %d, %v)", res, unit, err)
+	}
+	if err := c.update(func(st *coordState) error {
+		st.Leases[0].Expires = time.Now().Add(time.Second)
+		st.Leases = append(st.Leases, lease{Unit: 1, Owner: "other:1", Expires: time.Now().Add(time.Second)})
+		st.Next = 2
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for unit := uint64(0); unit < 2; unit++ {
+		if err := c.renew(unit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var st coordState
+	if err := readJSONFile(path, &st); err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(st.Leases[0].Expires) < coordLease-time.Minute {
+		t.Fatalf("own lease not renewed: expires %v", st.Leases[0].Expires)
+	}
+	if time.Until(st.Leases[1].Expires) > time.Minute {
+		t.Fatalf("other owner's lease renewed: expires %v", st.Leases[1].Expires)
+	}
+}
+
+// 扫描时间超过租约的单元在扫描期间持续续约，不会过期
+func TestCoordinatorRenewsDuringScan(t *testing.T) {
+	smallUnits(t)
+	coordLease = 90 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "coord.json")
+	id := coordJob(mulBase(1), coordUnitSize-1)
+	started := make(chan struct{})
+	sp := &searchPlan{units: coordUnitSize, step: 1, scan: func(ctx context.Context, start, end uint64, rep *progressReporter) (uint64, bool) {
+		close(started)
+		time.Sleep(4 * coordLease)
+		return 0, false
+	}}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := sp.solveShared(context.Background(), path, id, 1, startProgress(nil, coordUnitSize, 0))
+		errc <- err
+	}()
+	<-started
+	for i := 0; i < 3; i++ {
+		time.Sleep(coordLease)
+		var st coordState
+		if err := readJSONFile(path, &st); err != nil {
+			t.Fatal(err)
+		}
+		if len(st.Leases) != 1 || time.Now().After(st.Leases[0].Expires) {
+			t.Fatalf("lease lapsed during scan: %v", st.Leases)
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
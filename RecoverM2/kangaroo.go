@@ -0,0 +1,296 @@
+package recoverm2
+
+import (
+	"context"
+	crand "crypto/rand"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+const (
+	// kangarooHerd 每个 goroutine 同时推进的袋鼠数（一半驯服、一半野生），共用一次批量求逆
+	kangarooHerd = 16
+	// kangarooMaxWork 总步数超过 kangarooMaxWork·√W 仍未碰撞时认为目标不在区间内
+	kangarooMaxWork = 32
+	// kangarooLinearLimit 区间宽度不超过该值时直接线性搜索
+	kangarooLinearLimit = 1 << 16
+	// kangarooMaxBits 区间上界的最大位数，跳距用 u128 记录
+	kangarooMaxBits = 120
+)
+
+// u128 128 位无符号整数，用于记录袋鼠跳过的距离
+type u128 struct {
+	hi, lo uint64
+}
+
+func (x u128) add(y u128) u128 {
+	lo, carry := bits.Add64(x.lo, y.lo, 0)
+	hi, _ := bits.Add64(x.hi, y.hi, carry)
+	return u128{hi, lo}
+}
+
+func (x u128) big() *big.Int {
+	res := new(big.Int).SetUint64(x.hi)
+	res.Lsh(res, 64)
+	return res.Or(res, new(big.Int).SetUint64(x.lo))
+}
+
+func u128FromBig(x *big.Int) u128 {
+	lo := new(big.Int).And(x, new(big.Int).SetUint64(math.MaxUint64))
+	hi := new(big.Int).Rsh(x, 64)
+	return u128{hi.Uint64(), lo.Uint64()}
+}
+
+// kangaroo 袋鼠当前位置与已跳距离。
+// 驯服袋鼠位于 dist·B，野生袋鼠位于 P' + dist·B，其中 P' = P − a·B = k'·B。
+type kangaroo struct {
+	pos  twistededwards.PointAffine
+	dist u128
+	tame bool
+}
+
+// dpEntry 可区分点存储中的记录
+type dpEntry struct {
+	dist u128
+	tame bool
+}
+
+// dpStore 所有 goroutine 共享的可区分点存储
+type dpStore struct {
+	mu     sync.Mutex
+	points map[[32]byte]dpEntry
+}
+
+// insert 存入可区分点；若该点已有记录则返回已有记录，不覆盖
+func (s *dpStore) insert(key [32]byte, e dpEntry) (dpEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.points[key]; ok {
+		return old, true
+	}
+	s.points[key] = e
+	return dpEntry{}, false
+}
+
+// kangarooParams 跳跃表与可区分点判定
+type kangarooParams struct {
+	base     twistededwards.PointAffine
+	target   twistededwards.PointAffine // P' = P − a·B
+	width    *big.Int                   // W = b − a
+	jumps    []u128                     // s_i = 2^i
+	jumpExt  []twistededwards.PointExtended
+	dpMask   uint64
+	maxSteps uint64 // 每个 goroutine 的步数上限
+	rand     io.Reader
+
+	mu  sync.Mutex
+	err error // 第一个读取随机源失败的错误
+}
+
+// fail 记录第一个错误
+func (kp *kangarooParams) fail(err error) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.err == nil {
+		kp.err = err
+	}
+}
+
+// lockedReader 使各 goroutine 可共用同一随机源
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
+
+// kangarooSolve 用并行 Pollard lambda（袋鼠）法在 [a, b] 内求 k 使 k·B = P，期望工作量 O(√(b−a))。
+// 各 goroutine 推进若干袋鼠，经过可区分点时写入共享存储，驯服与野生袋鼠在同一可区分点相遇即得解。
+// 袋鼠的初始位置取自 rand（nil 时为 crypto/rand），progress 的 total 为各 goroutine 步数上限之和。
+func kangarooSolve(ctx context.Context, rand io.Reader, base, P twistededwards.PointAffine, a, b *big.Int, numThreads int, progress ProgressFunc, interval time.Duration) (*big.Int, bool, error) {
+	if a.Sign() < 0 || b.Cmp(a) < 0 {
+		return nil, false, ErrInvalidRange
+	}
+	if b.BitLen() > kangarooMaxBits {
+		return nil, false, ErrRangeTooWide
+	}
+	if rand == nil {
+		rand = crand.Reader
+	}
+	if numThreads < 1 {
+		numThreads = 1
+	}
+	width := new(big.Int).Sub(b, a)
+
+	// P' = P − a·B
+	var target, ind twistededwards.PointAffine
+	ind.ScalarMultiplication(&base, a)
+	ind.Neg(&ind)
+	target.Add(&P, &ind)
+
+	if width.Cmp(big.NewInt(kangarooLinearLimit)) <= 0 {
+		rep := startProgress(progress, width.Uint64()+1, interval)
+		k, ok := linearPlan(base, target, width.Uint64()).solve(ctx, numThreads, rep)
+		rep.finish()
+		if !ok {
+			return nil, false, nil
+		}
+		return new(big.Int).Add(a, new(big.Int).SetUint64(k)), true, nil
+	}
+
+	kp := newKangarooParams(base, target, width, numThreads)
+	kp.rand = &lockedReader{r: rand}
+	store := &dpStore{points: make(map[[32]byte]dpEntry)}
+	rep := startProgress(progress, kp.maxSteps*uint64(numThreads)*kangarooHerd, interval)
+	defer rep.finish()
+
+	k, ok := firstResult(ctx, numThreads, func(ctx context.Context, _ int) (*big.Int, bool) {
+		return kp.run(ctx, store, rep)
+	})
+	if !ok {
+		return nil, false, kp.err
+	}
+	return k.Add(k, a), true, nil
+}
+
+func newKangarooParams(base, target twistededwards.PointAffine, width *big.Int, numThreads int) *kangarooParams {
+	herdTotal := numThreads * kangarooHerd
+	sqrtW := new(big.Int).Sqrt(width)
+	sqrtWf, _ := new(big.Float).SetInt(sqrtW).Float64()
+
+	// 平均跳距约为 N·√W/4（N 为袋鼠总数）
+	mean := float64(herdTotal) * sqrtWf / 4
+	n := 1
+	for (math.Exp2(float64(n))-1)/float64(n) < mean && n < 120 {
+		n++
+	}
+	kp := &kangarooParams{
+		base:    base,
+		target:  target,
+		width:   width,
+		jumps:   make([]u128, n),
+		jumpExt: make([]twistededwards.PointExtended, n),
+	}
+	var jump twistededwards.PointAffine
+	jump.Set(&base)
+	for i := 0; i < n; i++ {
+		if i < 64 {
+			kp.jumps[i] = u128{0, 1 << uint(i)}
+		} else {
+			kp.jumps[i] = u128{1 << uint(i-64), 0}
+		}
+		kp.jumpExt[i].FromAffine(&jump)
+		jump.Double(&jump)
+	}
+
+	// 可区分点间隔约 √W/(16·N)，碰撞后的额外开销约为总工作量的 1/16
+	dpBits := 0
+	for math.Exp2(float64(dpBits+1)) <= sqrtWf/float64(16*herdTotal) && dpBits < 48 {
+		dpBits++
+	}
+	kp.dpMask = uint64(1)<<uint(dpBits) - 1
+	kp.maxSteps = uint64(kangarooMaxWork*sqrtWf/float64(numThreads)) + 1
+	return kp
+}
+
+// spawn 在随机位置放置袋鼠：驯服袋鼠 dist ∈ [W/4, 3W/4)，野生袋鼠 dist ∈ [0, W/2)
+func (kp *kangarooParams) spawn(k *kangaroo, tame bool) error {
+	half := new(big.Int).Rsh(kp.width, 1)
+	d, err := crand.Int(kp.rand, half)
+	if err != nil {
+		return err
+	}
+	k.tame = tame
+	if tame {
+		d.Add(d, new(big.Int).Rsh(kp.width, 2))
+		k.pos.ScalarMultiplication(&kp.base, d)
+	} else {
+		k.pos.ScalarMultiplication(&kp.base, d)
+		k.pos.Add(&k.pos, &kp.target)
+	}
+	k.dist = u128FromBig(d)
+	return nil
+}
+
+// run 推进一群袋鼠直到找到 k'、上下文取消、步数用尽或随机源出错（记录在 kp.err 中）
+func (kp *kangarooParams) run(ctx context.Context, store *dpStore, rep *progressReporter) (*big.Int, bool) {
+	herd := make([]kangaroo, kangarooHerd)
+	for i := range herd {
+		if err := kp.spawn(&herd[i], i%2 == 0); err != nil {
+			kp.fail(err)
+			return nil, false
+		}
+	}
+	next := make([]twistededwards.PointExtended, kangarooHerd)
+	zs := make([]fr.Element, kangarooHerd)
+
+	for step := uint64(0); step < kp.maxSteps; step++ {
+		if step%1024 == 0 {
+			if ctx.Err() != nil {
+				return nil, false
+			}
+			if step > 0 {
+				rep.add(1024 * kangarooHerd)
+			}
+		}
+		for i := range herd {
+			k := &herd[i]
+			key := k.pos.Bytes()
+			low := pointKeyBytes(key)
+			if low&kp.dpMask == 0 {
+				if old, hit := store.insert(key, dpEntry{k.dist, k.tame}); hit {
+					if old.tame != k.tame {
+						if res, ok := kp.collide(old, dpEntry{k.dist, k.tame}); ok {
+							return res, true
+						}
+					}
+					// 同类袋鼠汇合后路径相同，重新放置
+					if err := kp.spawn(k, k.tame); err != nil {
+						kp.fail(err)
+						return nil, false
+					}
+				}
+			}
+			idx := int(low>>32) % len(kp.jumps)
+			next[i].FromAffine(&k.pos)
+			next[i].Add(&next[i], &kp.jumpExt[idx])
+			zs[i] = next[i].Z
+			k.dist = k.dist.add(kp.jumps[idx])
+		}
+		inv := fr.BatchInvert(zs)
+		for i := range herd {
+			herd[i].pos.X.Mul(&next[i].X, &inv[i])
+			herd[i].pos.Y.Mul(&next[i].Y, &inv[i])
+		}
+	}
+	return nil, false
+}
+
+// collide 由驯服与野生袋鼠在同一点的距离求 k' = d_tame − d_wild，并验证
+func (kp *kangarooParams) collide(e1, e2 dpEntry) (*big.Int, bool) {
+	tame, wild := e1, e2
+	if !tame.tame {
+		tame, wild = e2, e1
+	}
+	k := new(big.Int).Sub(tame.dist.big(), wild.dist.big())
+	if k.Sign() < 0 || k.Cmp(kp.width) > 0 {
+		return nil, false
+	}
+	var check twistededwards.PointAffine
+	check.ScalarMultiplication(&kp.base, k)
+	if !check.Equal(&kp.target) {
+		return nil, false
+	}
+	return k, true
+}
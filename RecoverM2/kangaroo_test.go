@@ -0,0 +1,40 @@
+package recoverm2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"testing/iotest"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+func TestKangarooRangeTooWide(t *testing.T) {
+	base := twistededwards.GetEdwardsCurve().Base
+	hi := new(big.Int).Lsh(big.NewInt(1), kangarooMaxBits)
+	_, err := Solve(context.Background(), base, base, big.NewInt(0), hi, nil, &Options{Algorithm: Kangaroo})
+	if !errors.Is(err, ErrRangeTooWide) {
+		t.Fatalf("got %v, want ErrRangeTooWide", err)
+	}
+}
+
+func TestKangarooRandError(t *testing.T) {
+	base := twistededwards.GetEdwardsCurve().Base
+	k := big.NewInt(1234567)
+	var target twistededwards.PointAffine
+	target.ScalarMultiplication(&base, k)
+	hi := big.NewInt(1 << 24)
+
+	errRand := errors.New("rand failure")
+	opts := &Options{Algorithm: Kangaroo, Threads: 2, Rand: iotest.ErrReader(errRand)}
+	if _, err := Solve(context.Background(), base, target, big.NewInt(0), hi, nil, opts); !errors.Is(err, errRand) {
+		t.Fatalf("got %v, want the reader's error", err)
+	}
+
+	opts.Rand = nil
+	got, err := Solve(context.Background(), base, target, big.NewInt(0), hi, nil, opts)
+	if err != nil || got.Cmp(k) != 0 {
+		t.Fatalf("got %v, %v, want %v", got, err, k)
+	}
+}
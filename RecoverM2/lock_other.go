@@ -0,0 +1,31 @@
+//go:build !unix
+
+package recoverm2
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// lockStale 锁文件超过该时长仍存在时认为持有者已退出
+const lockStale = time.Minute
+
+// lockFile 不支持 flock 的平台上以独占创建锁文件代替，返回解锁函数
+func lockFile(path string) (func() error, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			f.Close()
+			return func() error { return os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package recoverm2
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 对锁文件加排他 flock，返回解锁函数
+func lockFile(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return f.Close()
+	}, nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package recoverm2
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile 不支持 mmap 的平台上整体读入内存
+func mapFile(path string) ([]byte, io.Closer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, io.NopCloser(nil), nil
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package recoverm2
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// mappedFile 只读内存映射
+type mappedFile struct {
+	data []byte
+}
+
+func (f *mappedFile) Close() error {
+	if f.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(f.data)
+	f.data = nil
+	return err
+}
+
+// mapFile 将整个文件只读映射到内存
+func mapFile(path string) ([]byte, io.Closer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil, ErrTableFormat
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &mappedFile{data: data}, nil
+}
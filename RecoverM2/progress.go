@@ -0,0 +1,68 @@
+package recoverm2
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressFunc 进度回调：done 为已检查的候选数（袋鼠法为已走步数），total 为预计总数。
+// 回调由同一个 goroutine 串行调用，不需要加锁。
+type ProgressFunc func(done, total uint64)
+
+// defaultProgressInterval 默认进度回调间隔
+const defaultProgressInterval = time.Second
+
+// progressReporter 汇总各 worker 的计数并定期回调
+type progressReporter struct {
+	done  atomic.Uint64
+	total uint64
+	fn    ProgressFunc
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// startProgress 启动定期回调；fn 为 nil 时只计数
+func startProgress(fn ProgressFunc, total uint64, interval time.Duration) *progressReporter {
+	p := &progressReporter{total: total, fn: fn, stop: make(chan struct{})}
+	if fn == nil {
+		return p
+	}
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.report()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *progressReporter) add(n uint64) {
+	p.done.Add(n)
+}
+
+// report 回调当前进度；按整块计数的 done 可能略超 total，截断到 total
+func (p *progressReporter) report() {
+	p.fn(min(p.done.Load(), p.total), p.total)
+}
+
+// finish 停止定期回调并做最后一次回调
+func (p *progressReporter) finish() {
+	if p.fn == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.report()
+}
@@ -1,8 +1,13 @@
-package main
+// Package recoverm2 在有界区间内求解离散对数 k·B = P，
+// 用于从 m·h 恢复金额 m、从 k·G 恢复小私钥等场景。
+package recoverm2
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"runtime"
 	"sync"
@@ -11,108 +16,186 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 )
 
-func main() {
-	// 定义曲线参数
-	curve := twistededwards.GetEdwardsCurve()
+// Algorithm 求解算法
+type Algorithm int
 
-	// 定义基点 G
-	G := curve.Base
+const (
+	Linear   Algorithm = iota // 分段并行线性搜索
+	BSGS                      // 小步大步法
+	Kangaroo                  // Pollard 袋鼠法
+)
 
-	// 目标点 P（已知的公钥点），示例值
-	var P twistededwards.PointAffine
-	P.Set(&G).ScalarMultiplication(&G, big.NewInt(12345678)) // 模拟一个已知私钥 12345678 的点
+// String 返回算法名：linear、bsgs 或 kangaroo
+func (a Algorithm) String() string {
+	switch a {
+	case Linear:
+		return "linear"
+	case BSGS:
+		return "bsgs"
+	case Kangaroo:
+		return "kangaroo"
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
 
-	// 设置暴力搜索的最大值
-	maxVal := int64(100000000) // 10^8
+// ParseAlgorithm 由算法名解析 Algorithm
+func ParseAlgorithm(s string) (Algorithm, error) {
+	for _, a := range []Algorithm{Linear, BSGS, Kangaroo} {
+		if s == a.String() {
+			return a, nil
+		}
+	}
+	return 0, errUnknownAlgorithm
+}
 
-	// 获取 CPU 核心数
-	numThreads := runtime.NumCPU()
-	fmt.Printf("使用 %d 个 CPU 核心并行搜索\n", numThreads)
+var (
+	ErrNotFound     = errors.New("recoverm2: discrete log not found in range")
+	ErrInvalidRange = errors.New("recoverm2: invalid range")
+	ErrRangeTooWide = errors.New("recoverm2: kangaroo supports upper bounds of at most 120 bits")
 
-	// 开始暴力破解
-	start := time.Now()
-	privKey := parallelBruteForceFindPrivateKey(G, P, maxVal, numThreads)
-	elapsed := time.Since(start)
+	errUnknownAlgorithm = errors.New("recoverm2: unknown algorithm")
+)
 
-	// 输出结果
-	if privKey.Cmp(big.NewInt(0)) > 0 {
-		fmt.Printf("找到私钥: %s\n", privKey.String())
-		fmt.Printf("破解耗时: %s\n", elapsed)
-	} else {
-		fmt.Println("未找到私钥，请增加搜索范围或检查参数！")
-	}
-}
+// Options 求解选项，零值表示线性搜索、使用全部 CPU 核心
+type Options struct {
+	Algorithm Algorithm
+	Threads   int // <= 0 时为 runtime.NumCPU()
 
-// parallelBruteForceFindPrivateKey 通过 goroutines 并行暴力破解
-func parallelBruteForceFindPrivateKey(G twistededwards.PointAffine, P twistededwards.PointAffine, maxVal int64, numThreads int) *big.Int {
-	var wg sync.WaitGroup
-	results := make(chan *big.Int, 1) // 用于接收找到的私钥
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// BSGS：Table 非空时使用预计算表，否则建内存表，项数不超过 BabySteps（0 表示 ⌈√(hi−lo+1)⌉）
+	Table     *Table
+	BabySteps uint64
 
-	// 计算每个线程的搜索区间
-	chunkSize := maxVal / int64(numThreads)
+	ProgressInterval time.Duration // 进度回调间隔，默认 1 秒
 
-	for i := 0; i < numThreads; i++ {
-		wg.Add(1)
-		start := int64(i)*chunkSize + 1
-		end := start + chunkSize - 1
+	// 袋鼠法：放置袋鼠的随机源，nil 时为 crypto/rand
+	Rand io.Reader
 
-		// 处理最后一个 goroutine 的边界情况
-		if i == numThreads-1 {
-			end = maxVal
-		}
+	// 线性搜索与小步大步法可断点续传：Checkpoint 为检查点文件路径，每 CheckpointInterval（默认 30 秒）
+	// 写入各 worker 已完成的子区间，文件已存在时从中继续。
+	Checkpoint         string
+	CheckpointInterval time.Duration
 
-		// 启动 goroutine 进行区间搜索
-		go func(start, end int64) {
-			defer wg.Done()
-			findPrivateKeyInRange(ctx, G, P, start, end, results)
-		}(start, end)
-	}
+	// Coordinator 为协作文件路径：多个进程用同一文件、同样的参数调用 Solve 即可分摊工作，
+	// 进度回调只统计本进程的工作量。与 Checkpoint 互斥。扫描中的工作单元持续续约，
+	// 进程退出后其单元在租约（10 分钟）到期后由其他进程接手。
+	Coordinator string
+}
 
-	// 等待结果或超时
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// Solve 在 [lo, hi] 内求 k 使 k·base = target。
+// 未找到返回 ErrNotFound；ctx 被取消或超时返回 ctx.Err()；袋鼠法的 hi 超过 120 位时返回 ErrRangeTooWide。
+// progress 可为 nil。
+func Solve(ctx context.Context, base, target twistededwards.PointAffine, lo, hi *big.Int, progress ProgressFunc, opts *Options) (*big.Int, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if lo.Sign() < 0 || hi.Cmp(lo) < 0 {
+		return nil, ErrInvalidRange
+	}
+	numThreads := opts.Threads
+	if numThreads <= 0 {
+		numThreads = runtime.NumCPU()
+	}
 
-	// 监听结果或超时
-	select {
-	case privKey := <-results:
-		return privKey
-	case <-ctx.Done():
-		return big.NewInt(0)
+	if opts.Checkpoint != "" && opts.Coordinator != "" {
+		return nil, errCheckpointOptions
+	}
+	if opts.Algorithm == Kangaroo {
+		if opts.Checkpoint != "" || opts.Coordinator != "" {
+			return nil, errCheckpointAlgorithm
+		}
+		k, ok, err := kangarooSolve(ctx, opts.Rand, base, target, lo, hi, numThreads, progress, opts.ProgressInterval)
+		if err != nil {
+			return nil, err
+		}
+		return solveResult(ctx, k, ok)
 	}
-}
 
-// findPrivateKeyInRange 在给定范围内搜索私钥
-func findPrivateKeyInRange(ctx context.Context, G twistededwards.PointAffine, P twistededwards.PointAffine, start, end int64, results chan<- *big.Int) {
-	var candidate twistededwards.PointAffine
-	var k big.Int
-
-	for i := start; i <= end; i++ {
-		// 检查是否已经找到私钥，提前退出
-		select {
-		case <-ctx.Done():
-			return
-		default:
+	// 线性搜索与小步大步法在 [0, hi − lo] 内求解 (target − lo·base) 的离散对数
+	width := new(big.Int).Sub(hi, lo)
+	if !width.IsUint64() || width.Uint64() == math.MaxUint64 {
+		return nil, ErrInvalidRange
+	}
+	var shifted, ind twistededwards.PointAffine
+	ind.ScalarMultiplication(&base, lo)
+	ind.Neg(&ind)
+	shifted.Add(&target, &ind)
+
+	var sp *searchPlan
+	switch opts.Algorithm {
+	case Linear:
+		sp = linearPlan(base, shifted, width.Uint64())
+	case BSGS:
+		table := opts.Table
+		if table == nil {
+			table = NewTable(base, BabySteps(width.Uint64()+1, opts.BabySteps), numThreads)
+		} else if !table.base.Equal(&base) {
+			return nil, ErrTableBase
 		}
+		sp = table.plan(shifted, width.Uint64())
+	default:
+		return nil, errUnknownAlgorithm
+	}
 
-		k.SetInt64(i)
+	rep := startProgress(progress, width.Uint64()+1, opts.ProgressInterval)
+	var k uint64
+	var ok bool
+	var err error
+	id := newJobID(base, target, lo, hi, opts.Algorithm, sp)
+	switch {
+	case opts.Coordinator != "":
+		k, ok, err = sp.solveShared(ctx, opts.Coordinator, id, numThreads, rep)
+	case opts.Checkpoint != "":
+		k, ok, err = sp.solveCheckpointed(ctx, opts.Checkpoint, id, numThreads, opts.CheckpointInterval, rep)
+	default:
+		k, ok = sp.solve(ctx, numThreads, rep)
+	}
+	rep.finish()
+	if err != nil {
+		return nil, err
+	}
 
-		// 计算 i * G
-		candidate.ScalarMultiplication(&G, &k)
+	if !ok {
+		return solveResult(ctx, nil, false)
+	}
+	res := new(big.Int).SetUint64(k)
+	return res.Add(res, lo), nil
+}
 
-		// 比较 candidate 和 P 是否相等
-		if candidate.Equal(&P) {
-			fmt.Printf("匹配成功，私钥为：%d\n", i)
-			results <- new(big.Int).Set(&k)
-			return
-		}
+// solveResult 区分未找到与被取消
+func solveResult(ctx context.Context, k *big.Int, ok bool) (*big.Int, error) {
+	if ok {
+		return k, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotFound
+}
 
-		// 每 10^6 次输出一次进度
-		if i%1000000 == 0 {
-			fmt.Printf("线程 [%d - %d] 进行中，当前尝试: %d\n", start, end, i)
-		}
+// firstResult 并行运行 workers 个任务，返回第一个成功的结果并取消其余任务
+func firstResult[T any](ctx context.Context, workers int, fn func(ctx context.Context, w int) (T, bool)) (T, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan T, 1)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			if res, ok := fn(ctx, w); ok {
+				select {
+				case results <- res:
+					cancel()
+				default:
+				}
+			}
+		}(w)
 	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	res, ok := <-results
+	return res, ok
 }
@@ -0,0 +1,65 @@
+package recoverm2
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// scanFunc 扫描单位区间 [start, end)，命中时返回 k（相对区间起点 lo）
+type scanFunc func(ctx context.Context, start, end uint64, rep *progressReporter) (uint64, bool)
+
+// searchPlan 把线性搜索与小步大步法统一成对单位区间 [0, units) 的扫描，
+// 以便并行、断点续传与多进程协作共用同一套分段逻辑。
+// 线性搜索每个单位是一个候选，小步大步法每个单位是一个大步（step = m 个候选）。
+type searchPlan struct {
+	units uint64
+	step  uint64
+	scan  scanFunc
+}
+
+// solve 将单位区间分段，由 goroutines 并行扫描
+func (sp *searchPlan) solve(ctx context.Context, numThreads int, rep *progressReporter) (uint64, bool) {
+	chunks := splitRange(0, sp.units, numThreads)
+	return firstResult(ctx, len(chunks), func(ctx context.Context, w int) (uint64, bool) {
+		return sp.scan(ctx, chunks[w][0], chunks[w][1], rep)
+	})
+}
+
+// linearPlan 在 [0, width] 内逐点搜索 k 使 k·G = P
+func linearPlan(G, P twistededwards.PointAffine, width uint64) *searchPlan {
+	scan := func(ctx context.Context, start, end uint64, rep *progressReporter) (uint64, bool) {
+		return findPrivateKeyInRange(ctx, G, P, start, end, rep)
+	}
+	return &searchPlan{units: width + 1, step: 1, scan: scan}
+}
+
+// findPrivateKeyInRange 在 [start, end) 内搜索：只做一次标量乘得到 start·G，
+// 之后每个候选由前一个加 G 得到，经 walkPoints 批量归一化后与 P 比较
+func findPrivateKeyInRange(ctx context.Context, G, P twistededwards.PointAffine, start, end uint64, rep *progressReporter) (uint64, bool) {
+	var first twistededwards.PointAffine
+	first.ScalarMultiplication(&G, new(big.Int).SetUint64(start))
+
+	var found, reported uint64
+	var ok bool
+	walkPoints(&first, &G, end-start, func(off uint64, candidate *twistededwards.PointAffine) bool {
+		// 每批检查一次是否已经找到私钥，提前退出
+		if off%walkBatch == 0 {
+			if ctx.Err() != nil {
+				return false
+			}
+			rep.add(off - reported)
+			reported = off
+		}
+		if candidate.Equal(&P) {
+			found, ok = start+off, true
+			return false
+		}
+		return true
+	})
+	if !ok && ctx.Err() == nil {
+		rep.add(end - start - reported)
+	}
+	return found, ok
+}
@@ -0,0 +1,181 @@
+package recoverm2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 表文件格式（小端）：
+//
+//	0   magic       8 字节 "LYBSGS\0\0"
+//	8   version     uint32
+//	12  entrySize   uint32
+//	16  base        32 字节压缩点
+//	48  m           uint64 小步数
+//	56  count       uint64 表项数
+//	64  checksum    sha256(头部前 64 字节 || 表项)
+//	96  表项        count 个 (key uint64, j uint32)，按 key 升序
+const (
+	tableMagic      = "LYBSGS\x00\x00"
+	tableVersion    = 1
+	tableHeaderSize = 96
+	tableEntrySize  = 12
+)
+
+var (
+	ErrTableFormat   = errors.New("recoverm2: malformed table file")
+	ErrTableVersion  = errors.New("recoverm2: unsupported table version")
+	ErrTableChecksum = errors.New("recoverm2: table checksum mismatch")
+	ErrTableBase     = errors.New("recoverm2: table was built for a different base point")
+)
+
+// TableFileName 按基点命名表文件，使 G 与 h 的表可放在同一目录
+func TableFileName(base *twistededwards.PointAffine) string {
+	b := base.Bytes()
+	return fmt.Sprintf("bsgs-%s.tbl", hex.EncodeToString(b[:8]))
+}
+
+// WriteTable 计算 m 项小步并写入表文件
+func WriteTable(path string, base twistededwards.PointAffine, m uint64, numThreads int) error {
+	if m == 0 || m > math.MaxUint32 {
+		return ErrTableFormat
+	}
+	entries := computeBabySteps(base, m, numThreads)
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].key != entries[b].key {
+			return entries[a].key < entries[b].key
+		}
+		return entries[a].j < entries[b].j
+	})
+
+	header := make([]byte, tableHeaderSize)
+	copy(header[0:8], tableMagic)
+	binary.LittleEndian.PutUint32(header[8:12], tableVersion)
+	binary.LittleEndian.PutUint32(header[12:16], tableEntrySize)
+	baseBytes := base.Bytes()
+	copy(header[16:48], baseBytes[:])
+	binary.LittleEndian.PutUint64(header[48:56], m)
+	binary.LittleEndian.PutUint64(header[56:64], uint64(len(entries)))
+
+	body := make([]byte, len(entries)*tableEntrySize)
+	for i, e := range entries {
+		binary.LittleEndian.PutUint64(body[i*tableEntrySize:], e.key)
+		binary.LittleEndian.PutUint32(body[i*tableEntrySize+8:], e.j)
+	}
+	sum := tableChecksum(header, body)
+	copy(header[64:96], sum[:])
+
+	return writeFileAtomic(path, header, body)
+}
+
+// writeFileAtomic 依次写入 parts 到临时文件，同步后改名为 path，避免留下半个文件
+func writeFileAtomic(path string, parts ...[]byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	for _, part := range parts {
+		if _, err := tmp.Write(part); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func tableChecksum(header, body []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	hash.Write(header[:64])
+	hash.Write(body)
+	var sum [sha256.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum
+}
+
+// fileIndex 映射到内存的有序表项，二分查找
+type fileIndex struct {
+	entries []byte
+	count   int
+}
+
+func (idx *fileIndex) keyAt(i int) uint64 {
+	return binary.LittleEndian.Uint64(idx.entries[i*tableEntrySize:])
+}
+
+func (idx *fileIndex) lookup(key uint64) []uint32 {
+	i := sort.Search(idx.count, func(i int) bool { return idx.keyAt(i) >= key })
+	var res []uint32
+	for ; i < idx.count && idx.keyAt(i) == key; i++ {
+		res = append(res, binary.LittleEndian.Uint32(idx.entries[i*tableEntrySize+8:]))
+	}
+	return res
+}
+
+// OpenTable 只读映射表文件并检查版本与基点；verify 为真时校验整个文件的 sha256。
+// 用完后调用 Close 解除映射，之后表不可再用。
+func OpenTable(path string, base twistededwards.PointAffine, verify bool) (*Table, error) {
+	data, closer, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTable(data, base, verify)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	t.closer = closer
+	return t, nil
+}
+
+func parseTable(data []byte, base twistededwards.PointAffine, verify bool) (*Table, error) {
+	if len(data) < tableHeaderSize || string(data[0:8]) != tableMagic {
+		return nil, ErrTableFormat
+	}
+	if binary.LittleEndian.Uint32(data[8:12]) != tableVersion {
+		return nil, ErrTableVersion
+	}
+	if binary.LittleEndian.Uint32(data[12:16]) != tableEntrySize {
+		return nil, ErrTableFormat
+	}
+	baseBytes := base.Bytes()
+	if !bytes.Equal(data[16:48], baseBytes[:]) {
+		return nil, ErrTableBase
+	}
+	m := binary.LittleEndian.Uint64(data[48:56])
+	count := binary.LittleEndian.Uint64(data[56:64])
+	// 先以文件长度约束 count，避免 count*tableEntrySize 溢出后恰好等于实际长度
+	bodySize := uint64(len(data) - tableHeaderSize)
+	if m == 0 || count != m || count > bodySize/tableEntrySize || bodySize != count*tableEntrySize {
+		return nil, ErrTableFormat
+	}
+	body := data[tableHeaderSize:]
+	if verify {
+		sum := tableChecksum(data[:tableHeaderSize], body)
+		if !bytes.Equal(sum[:], data[64:96]) {
+			return nil, ErrTableChecksum
+		}
+	}
+	return &Table{
+		base:  base,
+		m:     m,
+		index: &fileIndex{entries: body, count: int(count)},
+	}, nil
+}
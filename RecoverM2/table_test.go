@@ -0,0 +1,35 @@
+package recoverm2
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 头部声明的 count 使 count*tableEntrySize 在 uint64 上回绕到实际长度时，必须拒绝
+func TestParseTableCountOverflow(t *testing.T) {
+	base := twistededwards.GetEdwardsCurve().Base
+	path := filepath.Join(t.TempDir(), "t.tbl")
+	if err := WriteTable(path, base, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseTable(data, base, true); err != nil {
+		t.Fatalf("valid table rejected: %v", err)
+	}
+
+	// 12·(2^62 + 1) ≡ 12 (mod 2^64)，与一项表的长度相同
+	const wrapped = 1<<62 + 1
+	binary.LittleEndian.PutUint64(data[48:56], wrapped)
+	binary.LittleEndian.PutUint64(data[56:64], wrapped)
+	if _, err := parseTable(data, base, false); !errors.Is(err, ErrTableFormat) {
+		t.Fatalf("got %v, want ErrTableFormat", err)
+	}
+}
@@ -0,0 +1,43 @@
+package recoverm2
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// walkBatch 每批归一化的点数
+const walkBatch = 1024
+
+// walkPoints 依次访问 start + i·step（0 ≤ i < count）。
+// 点加在扩展坐标下进行，每 walkBatch 个点用 Montgomery 批量求逆一次性转换为仿射坐标。
+// visit 返回 false 时提前结束，walkPoints 返回 false。
+func walkPoints(start, step *twistededwards.PointAffine, count uint64, visit func(i uint64, p *twistededwards.PointAffine) bool) bool {
+	var cur, stepExt twistededwards.PointExtended
+	cur.FromAffine(start)
+	stepExt.FromAffine(step)
+
+	batch := make([]twistededwards.PointExtended, walkBatch)
+	zs := make([]fr.Element, walkBatch)
+	var p twistededwards.PointAffine
+	for done := uint64(0); done < count; {
+		n := uint64(walkBatch)
+		if count-done < n {
+			n = count - done
+		}
+		for i := uint64(0); i < n; i++ {
+			batch[i] = cur
+			zs[i] = cur.Z
+			cur.Add(&cur, &stepExt)
+		}
+		inv := fr.BatchInvert(zs[:n])
+		for i := uint64(0); i < n; i++ {
+			p.X.Mul(&batch[i].X, &inv[i])
+			p.Y.Mul(&batch[i].Y, &inv[i])
+			if !visit(done+i, &p) {
+				return false
+			}
+		}
+		done += n
+	}
+	return true
+}
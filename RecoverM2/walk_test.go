@@ -0,0 +1,96 @@
+package recoverm2
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// benchCandidates 每轮基准测试搜索的候选数，目标点不在区间内，两种方法都扫完全部候选
+const benchCandidates = 1 << 14
+
+// scalarMulSearch 旧的线性搜索：每个候选 i 都从头计算 i·G
+func scalarMulSearch(G, P twistededwards.PointAffine, n uint64) (uint64, bool) {
+	var candidate twistededwards.PointAffine
+	var k big.Int
+	for i := uint64(0); i < n; i++ {
+		k.SetUint64(i)
+		candidate.ScalarMultiplication(&G, &k)
+		if candidate.Equal(&P) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// benchTarget 返回基点与区间 [0, benchCandidates) 之外的目标点
+func benchTarget() (twistededwards.PointAffine, twistededwards.PointAffine) {
+	G := twistededwards.GetEdwardsCurve().Base
+	var P twistededwards.PointAffine
+	P.ScalarMultiplication(&G, big.NewInt(benchCandidates))
+	return G, P
+}
+
+// 批量归一化得到的点与逐个仿射点加一致，跨越 walkBatch 边界且最后一批不满
+func TestWalkPoints(t *testing.T) {
+	start, step := mulBase(12345), mulBase(977)
+	const count = 2*walkBatch + 452
+	want := start
+	var visited uint64
+	ok := walkPoints(&start, &step, count, func(i uint64, p *twistededwards.PointAffine) bool {
+		if i != visited {
+			t.Fatalf("visited %d, want %d", i, visited)
+		}
+		if !p.Equal(&want) {
+			t.Fatalf("point %d differs from start + %d·step", i, i)
+		}
+		want.Add(&want, &step)
+		visited++
+		return true
+	})
+	if !ok || visited != count {
+		t.Fatalf("walk returned %v after %d points, want true after %d", ok, visited, count)
+	}
+
+	// visit 在第二批的第一个点返回 false 时立即结束
+	visited = 0
+	ok = walkPoints(&start, &step, count, func(i uint64, p *twistededwards.PointAffine) bool {
+		visited++
+		return i != walkBatch
+	})
+	if ok || visited != walkBatch+1 {
+		t.Fatalf("early stop: returned %v after %d points, want false after %d", ok, visited, walkBatch+1)
+	}
+
+	if !walkPoints(&start, &step, 0, func(uint64, *twistededwards.PointAffine) bool {
+		t.Fatal("visited a point with count 0")
+		return false
+	}) {
+		t.Fatal("empty walk returned false")
+	}
+}
+
+// go test -bench . ./RecoverM2 比较逐个标量乘与批量点加两种线性搜索（单线程）
+func BenchmarkScalarMulSearch(b *testing.B) {
+	G, P := benchTarget()
+	for i := 0; i < b.N; i++ {
+		if _, ok := scalarMulSearch(G, P, benchCandidates); ok {
+			b.Fatal("unexpected hit")
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/benchCandidates, "ns/candidate")
+}
+
+func BenchmarkLinearWalk(b *testing.B) {
+	G, P := benchTarget()
+	hi := big.NewInt(benchCandidates - 1)
+	opts := &Options{Algorithm: Linear, Threads: 1}
+	for i := 0; i < b.N; i++ {
+		if _, err := Solve(context.Background(), G, P, big.NewInt(0), hi, nil, opts); err != ErrNotFound {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/benchCandidates, "ns/candidate")
+}
@@ -0,0 +1,295 @@
+// Package reserveproof 实现储备证明：交易所在一组一次性地址输出（环）中证明自己拥有若干输出、
+// 其金额之和为公开的 Total，而不泄露具体是哪些输出。每个被计入的输出附带链接标签，
+// 已花费的输出（其标签已出现在链上）与重复计入的输出都会被发现。
+package reserveproof
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 盲化基点 U、V、K 的域分隔标签
+const (
+	uDomain = "LYcode/ReserveProof/U/v2"
+	vDomain = "LYcode/ReserveProof/V/v1"
+	kDomain = "LYcode/ReserveProof/K/v1"
+)
+
+var (
+	ErrEmptyRing      = errors.New("reserveproof: empty ring")
+	ErrInvalidKey     = errors.New("reserveproof: key does not match ring output")
+	ErrDuplicateKey   = errors.New("reserveproof: output counted more than once")
+	ErrSpentOutput    = errors.New("reserveproof: output already spent")
+	ErrTotalOverflow  = errors.New("reserveproof: total amount overflows uint64")
+	ErrInvalidReserve = errors.New("reserveproof: invalid reserve proof")
+)
+
+// Params 公共参数：金额承诺参数 (G, h) 与成员证明中盲化 H_p(ota) 所用的基点 U、V、K（离散对数均未知）
+type Params struct {
+	Amount  confamount.Params
+	U, V, K twistededwards.PointAffine
+}
+
+// NewParams 由金额加密参数构造储备证明参数
+func NewParams(amount confamount.Params) *Params {
+	return &Params{
+		Amount: amount,
+		U:      curveutil.HashToPoint(uDomain),
+		V:      curveutil.HashToPoint(vDomain),
+		K:      curveutil.HashToPoint(kDomain),
+	}
+}
+
+// Output 链上的一个输出：一次性地址 ota 与金额承诺 Y = r·G + m·h
+type Output struct {
+	Ota        twistededwards.PointAffine
+	Commitment twistededwards.PointAffine
+}
+
+// Key 证明者拥有的输出：环中下标、一次性私钥 x（ota = x·G）以及承诺的打开 (m, r)。
+// r 须为所有者可知，例如由与发送方的共享秘密派生。
+type Key struct {
+	Index int
+	X     *big.Int
+	M     uint64
+	R     *big.Int
+}
+
+// OwnedOutput 一个被计入储备的输出：链接标签 J = x·H_p(ota)、重新承诺同一金额的 C'，以及成员证明
+type OwnedOutput struct {
+	Tag    twistededwards.PointAffine
+	Pseudo twistededwards.PointAffine
+	Proof  MembershipProof
+}
+
+// ReserveProof 储备证明：各输出的成员证明，以及 Σ C' − Total·h = ΔR·G 的平衡证明
+type ReserveProof struct {
+	Outputs []OwnedOutput
+	Total   uint64
+	Sum     confamount.BalanceProof
+}
+
+// Tag 返回一次性私钥 x 对应的链接标签 x·H_p(ota)，ota = x·G。
+// 它与环签名花费该输出时公开的一次性标志相同，见 curveutil.KeyImageBase。
+func Tag(params *Params, x *big.Int) twistededwards.PointAffine {
+	var ota, J twistededwards.PointAffine
+	ota.ScalarMultiplication(&params.Amount.G, x)
+	J = curveutil.KeyImageBase(&ota)
+	J.ScalarMultiplication(&J, x)
+	return J
+}
+
+// Prove 对环 ring 中由 keys 指定的输出生成储备证明，context 为审计方给出的上下文（如区块高度）
+func Prove(rand io.Reader, params *Params, ring []Output, keys []Key, context []byte) (*ReserveProof, error) {
+	if len(ring) == 0 {
+		return nil, ErrEmptyRing
+	}
+	padded := padRing(ring)
+	proof := &ReserveProof{Outputs: make([]OwnedOutput, len(keys))}
+	seen := make(map[int]bool, len(keys))
+	pseudoRs := make([]*big.Int, len(keys))
+	pseudos := make([]twistededwards.PointAffine, len(keys))
+	for j := range keys {
+		key := &keys[j]
+		if key.Index < 0 || key.Index >= len(ring) || curveutil.ModOrder(key.X).Sign() == 0 {
+			return nil, ErrInvalidKey
+		}
+		if seen[key.Index] {
+			return nil, ErrDuplicateKey
+		}
+		seen[key.Index] = true
+		var ota twistededwards.PointAffine
+		ota.ScalarMultiplication(&params.Amount.G, key.X)
+		m := new(big.Int).SetUint64(key.M)
+		commitment := params.Amount.Commit(m, key.R)
+		if !ota.Equal(&ring[key.Index].Ota) || !commitment.Equal(&ring[key.Index].Commitment) {
+			return nil, ErrInvalidKey
+		}
+		if proof.Total+key.M < proof.Total {
+			return nil, ErrTotalOverflow
+		}
+		proof.Total += key.M
+
+		// C' = r'·G + m·h，C_l − C' = (r − r')·G
+		rPrime, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		out := &proof.Outputs[j]
+		out.Tag = Tag(params, key.X)
+		out.Pseudo = params.Amount.Commit(m, rPrime)
+		w := &membershipWitness{index: key.Index, x: key.X, delta: new(big.Int).Sub(key.R, rPrime)}
+		mp, err := proveMembership(rand, params, padded, &out.Tag, &out.Pseudo, w, context)
+		if err != nil {
+			return nil, err
+		}
+		out.Proof = *mp
+		pseudoRs[j], pseudos[j] = rPrime, out.Pseudo
+	}
+
+	sum, err := confamount.ProveBalance(rand, &params.Amount, pseudos, nil, proof.Total, pseudoRs, nil)
+	if err != nil {
+		return nil, err
+	}
+	proof.Sum = *sum
+	return proof, nil
+}
+
+// Verify 验证储备证明。spent 为链上已公开的花费标签集合（可为 nil），
+// 证明中任一输出已花费时返回 ErrSpentOutput；通过后 proof.Total 即为未花费输出的金额之和。
+func Verify(params *Params, ring []Output, proof *ReserveProof, spent map[twistededwards.PointAffine]bool, context []byte) error {
+	if len(ring) == 0 {
+		return ErrEmptyRing
+	}
+	padded := padRing(ring)
+	seen := make(map[twistededwards.PointAffine]bool, len(proof.Outputs))
+	pseudos := make([]twistededwards.PointAffine, len(proof.Outputs))
+	for j := range proof.Outputs {
+		out := &proof.Outputs[j]
+		if out.Tag.IsZero() {
+			return ErrInvalidReserve
+		}
+		if seen[out.Tag] {
+			return ErrDuplicateKey
+		}
+		seen[out.Tag] = true
+		if !verifyMembership(params, padded, &out.Tag, &out.Pseudo, &out.Proof, context) {
+			return ErrInvalidReserve
+		}
+		pseudos[j] = out.Pseudo
+	}
+	if confamount.VerifyBalance(&params.Amount, pseudos, nil, proof.Total, &proof.Sum) != nil {
+		return ErrInvalidReserve
+	}
+	for j := range proof.Outputs {
+		if spent[proof.Outputs[j].Tag] {
+			return ErrSpentOutput
+		}
+	}
+	return nil
+}
+
+// MarshalBinary 序列化为 count（4 字节）|| total（8 字节）|| sum || 各输出（n || J || C' || Ê || R || 证明）
+func (proof *ReserveProof) MarshalBinary() ([]byte, error) {
+	res := binary.BigEndian.AppendUint32(nil, uint32(len(proof.Outputs)))
+	res = binary.BigEndian.AppendUint64(res, proof.Total)
+	sum, err := proof.Sum.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, sum...)
+	for j := range proof.Outputs {
+		out := &proof.Outputs[j]
+		mp := &out.Proof
+		res = append(res, byte(len(mp.Cl)))
+		for _, p := range []*twistededwards.PointAffine{&out.Tag, &out.Pseudo, &mp.Base, &mp.Blind} {
+			b := p.Bytes()
+			res = append(res, b[:]...)
+		}
+		for _, ps := range [][]twistededwards.PointAffine{mp.Cl, mp.Ca, mp.Cb, mp.X, mp.W, mp.Q, mp.B, mp.P, mp.Y} {
+			for i := range ps {
+				b := ps[i].Bytes()
+				res = append(res, b[:]...)
+			}
+		}
+		for _, ks := range [][]big.Int{mp.F, mp.Za, mp.Zb, {mp.Z, mp.Zc, mp.Ze, mp.Zr, mp.Zx, mp.Zy}} {
+			for i := range ks {
+				b := curveutil.ScalarBytes(&ks[i])
+				res = append(res, b[:]...)
+			}
+		}
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明，并检查各点合法
+func (proof *ReserveProof) UnmarshalBinary(data []byte) error {
+	header := 4 + 8 + confamount.BalanceProofSize
+	if len(data) < header {
+		return ErrInvalidReserve
+	}
+	count := int(binary.BigEndian.Uint32(data))
+	total := binary.BigEndian.Uint64(data[4:])
+	var sum confamount.BalanceProof
+	if err := sum.UnmarshalBinary(data[12:header]); err != nil {
+		return err
+	}
+
+	off := header
+	readPoints := func(ps []twistededwards.PointAffine) error {
+		for i := range ps {
+			if len(data)-off < curveutil.PointSize {
+				return ErrInvalidReserve
+			}
+			p, err := curveutil.PointFromBytes(data[off : off+curveutil.PointSize])
+			if err != nil {
+				return err
+			}
+			ps[i] = p
+			off += curveutil.PointSize
+		}
+		return nil
+	}
+	readScalars := func(ks []big.Int) error {
+		for i := range ks {
+			if len(data)-off < curveutil.ScalarSize {
+				return ErrInvalidReserve
+			}
+			k, err := curveutil.ScalarFromBytes(data[off : off+curveutil.ScalarSize])
+			if err != nil {
+				return err
+			}
+			ks[i].Set(k)
+			off += curveutil.ScalarSize
+		}
+		return nil
+	}
+
+	var outputs []OwnedOutput
+	for j := 0; j < count; j++ {
+		if off >= len(data) {
+			return ErrInvalidReserve
+		}
+		n := int(data[off])
+		off++
+		var out OwnedOutput
+		mp := &out.Proof
+		head := make([]twistededwards.PointAffine, 4)
+		if err := readPoints(head); err != nil {
+			return err
+		}
+		out.Tag, out.Pseudo, mp.Base, mp.Blind = head[0], head[1], head[2], head[3]
+		for _, ps := range []*[]twistededwards.PointAffine{&mp.Cl, &mp.Ca, &mp.Cb, &mp.X, &mp.W, &mp.Q, &mp.B, &mp.P, &mp.Y} {
+			*ps = make([]twistededwards.PointAffine, n)
+			if err := readPoints(*ps); err != nil {
+				return err
+			}
+		}
+		for _, ks := range []*[]big.Int{&mp.F, &mp.Za, &mp.Zb} {
+			*ks = make([]big.Int, n)
+			if err := readScalars(*ks); err != nil {
+				return err
+			}
+		}
+		zs := make([]big.Int, 6)
+		if err := readScalars(zs); err != nil {
+			return err
+		}
+		for i, z := range []*big.Int{&mp.Z, &mp.Zc, &mp.Ze, &mp.Zr, &mp.Zx, &mp.Zy} {
+			z.Set(&zs[i])
+		}
+		outputs = append(outputs, out)
+	}
+	if off != len(data) {
+		return ErrInvalidReserve
+	}
+	proof.Outputs, proof.Total, proof.Sum = outputs, total, sum
+	return nil
+}
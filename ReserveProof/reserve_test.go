@@ -0,0 +1,154 @@
+package reserveproof
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+var testContext = []byte("height 100")
+
+// newTestRing 生成 size 个输出的环，并返回其中 owned 各下标的密钥（金额为 10·(下标 + 1)）
+func newTestRing(t *testing.T, params *Params, size int, owned ...int) ([]Output, []Key) {
+	t.Helper()
+	ring := make([]Output, size)
+	keys := make([]Key, size)
+	for i := range ring {
+		x, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = Key{Index: i, X: x, M: 10 * uint64(i+1), R: r}
+		ring[i].Ota.ScalarMultiplication(&params.Amount.G, x)
+		ring[i].Commitment = params.Amount.Commit(new(big.Int).SetUint64(keys[i].M), r)
+	}
+	res := make([]Key, len(owned))
+	for j, i := range owned {
+		res[j] = keys[i]
+	}
+	return ring, res
+}
+
+func TestProveVerify(t *testing.T) {
+	params := NewParams(confamount.DefaultParams())
+	ring, keys := newTestRing(t, params, 5, 1, 3)
+	proof, err := Prove(rand.Reader, params, ring, keys, testContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Total != 20+40 {
+		t.Fatalf("total %d, want 60", proof.Total)
+	}
+	if err := Verify(params, ring, proof, nil, testContext); err != nil {
+		t.Fatal(err)
+	}
+
+	// 公开的总额必须与承诺一致
+	proof.Total++
+	if err := Verify(params, ring, proof, nil, testContext); !errors.Is(err, ErrInvalidReserve) {
+		t.Fatalf("wrong total: got %v, want ErrInvalidReserve", err)
+	}
+	proof.Total--
+
+	if err := Verify(params, ring, proof, nil, []byte("height 101")); !errors.Is(err, ErrInvalidReserve) {
+		t.Fatalf("other context: got %v, want ErrInvalidReserve", err)
+	}
+	other, _ := newTestRing(t, params, 5)
+	if err := Verify(params, other, proof, nil, testContext); !errors.Is(err, ErrInvalidReserve) {
+		t.Fatalf("other ring: got %v, want ErrInvalidReserve", err)
+	}
+}
+
+func TestDuplicateKey(t *testing.T) {
+	params := NewParams(confamount.DefaultParams())
+	ring, keys := newTestRing(t, params, 4, 2)
+	if _, err := Prove(rand.Reader, params, ring, []Key{keys[0], keys[0]}, testContext); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("same index twice: got %v, want ErrDuplicateKey", err)
+	}
+
+	// 同一输出在环中出现两次时证明者可以分别计入，但两者的标签相同
+	ring = append(ring, ring[2])
+	dup := keys[0]
+	dup.Index = len(ring) - 1
+	proof, err := Prove(rand.Reader, params, ring, []Key{keys[0], dup}, testContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(params, ring, proof, nil, testContext); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("same output twice: got %v, want ErrDuplicateKey", err)
+	}
+
+	// 重复证明中的同一项
+	proof, err = Prove(rand.Reader, params, ring, keys, testContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Outputs = append(proof.Outputs, proof.Outputs[0])
+	if err := Verify(params, ring, proof, nil, testContext); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("repeated entry: got %v, want ErrDuplicateKey", err)
+	}
+}
+
+func TestSpentOutput(t *testing.T) {
+	params := NewParams(confamount.DefaultParams())
+	ring, keys := newTestRing(t, params, 4, 0, 3)
+	proof, err := Prove(rand.Reader, params, ring, keys, testContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, decoys := newTestRing(t, params, 2, 0, 1)
+	spent := map[twistededwards.PointAffine]bool{Tag(params, decoys[0].X): true, Tag(params, decoys[1].X): true}
+	if err := Verify(params, ring, proof, spent, testContext); err != nil {
+		t.Fatalf("unrelated spent tags: %v", err)
+	}
+	spent[Tag(params, keys[1].X)] = true
+	if err := Verify(params, ring, proof, spent, testContext); !errors.Is(err, ErrSpentOutput) {
+		t.Fatalf("got %v, want ErrSpentOutput", err)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	params := NewParams(confamount.DefaultParams())
+	ring, keys := newTestRing(t, params, 3, 0, 2)
+	proof, err := Prove(rand.Reader, params, ring, keys, testContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ReserveProof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Total != proof.Total || len(decoded.Outputs) != len(proof.Outputs) {
+		t.Fatalf("decoded total %d with %d outputs", decoded.Total, len(decoded.Outputs))
+	}
+	if err := Verify(params, ring, &decoded, nil, testContext); err != nil {
+		t.Fatal(err)
+	}
+	again, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatal("re-encoding differs")
+	}
+
+	for _, bad := range [][]byte{data[:len(data)-1], data[:20], append(append([]byte(nil), data...), 0)} {
+		if err := new(ReserveProof).UnmarshalBinary(bad); err == nil {
+			t.Fatalf("accepted %d of %d bytes", len(bad), len(data))
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package reserveproof
+
+import (
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// membershipDomain 成员证明挑战的域分隔标签
+const membershipDomain = "LYcode/ReserveProof/Membership/v2"
+
+// MembershipProof 一对多证明（Groth-Kohlweiss 位承诺）：证明者知道环中第 l 个输出的一次性私钥 x
+// 与承诺随机数差 δ，使 M_l = x·G、C_l − C' = δ·G 且链接标签 J = x·E_l（E_i = H_p(M_i)），而不泄露 l。
+// E_l 以 Ê = E_l + β·U 的形式盲化公开，标签关系拆成对 (x, δ, β, γ, xβ, xγ) 的线性等式：
+//
+//	Ê − E_l = β·U，R = β·V + γ·K，x·R = (xβ)·V + (xγ)·K，J = x·Ê − (xβ)·U
+//
+// 第三式保证 xβ 确为 x 与 β 之积，于是 J = x·(Ê − β·U) = x·E_l。
+// 各式的响应都形如 z = w·x^n − Σ ρ_k·x^k，同一秘密在各式中共用 z，三个一对多等式共用下标位承诺。
+type MembershipProof struct {
+	Base, Blind           twistededwards.PointAffine   // Ê 与 R
+	Cl, Ca, Cb            []twistededwards.PointAffine // 下标各位的承诺，各 n 个
+	X, W, Q               []twistededwards.PointAffine // 一对多等式的多项式系数承诺，各 n 个
+	B, P, Y               []twistededwards.PointAffine // 标签线性等式的多项式系数承诺，各 n 个
+	F, Za, Zb             []big.Int                    // 各 n 个
+	Z, Zc, Ze, Zr, Zx, Zy big.Int                      // x、δ、β、γ、xβ、xγ 的响应
+}
+
+// membershipWitness 成员证明的证据
+type membershipWitness struct {
+	index int
+	x     *big.Int // 一次性私钥
+	delta *big.Int // C_l − C' 的离散对数
+}
+
+// ringBits 返回填充后的环大小 2^n 对应的 n（至少为 1）
+func ringBits(size int) int {
+	n := 1
+	for 1<<n < size {
+		n++
+	}
+	return n
+}
+
+// padRing 重复最后一个输出将环填充到 2^n 个
+func padRing(ring []Output) []Output {
+	n := ringBits(len(ring))
+	padded := append([]Output(nil), ring...)
+	for len(padded) < 1<<n {
+		padded = append(padded, ring[len(ring)-1])
+	}
+	return padded
+}
+
+// bit 返回 i 的第 j 位
+func bit(i, j int) int {
+	return (i >> j) & 1
+}
+
+// offsets 返回 C_i − C'
+func offsets(ring []Output, pseudo *twistededwards.PointAffine) []twistededwards.PointAffine {
+	var neg twistededwards.PointAffine
+	neg.Neg(pseudo)
+	res := make([]twistededwards.PointAffine, len(ring))
+	for i := range ring {
+		res[i].Add(&ring[i].Commitment, &neg)
+	}
+	return res
+}
+
+// keyImageOffsets 返回 Ê − E_i
+func keyImageOffsets(ring []Output, base *twistededwards.PointAffine) []twistededwards.PointAffine {
+	res := make([]twistededwards.PointAffine, len(ring))
+	for i := range ring {
+		E := curveutil.KeyImageBase(&ring[i].Ota)
+		E.Neg(&E)
+		res[i].Add(base, &E)
+	}
+	return res
+}
+
+// proveMembership 对填充后的环 ring 生成成员证明
+func proveMembership(rand io.Reader, params *Params, ring []Output, tag, pseudo *twistededwards.PointAffine, w *membershipWitness, context []byte) (*MembershipProof, error) {
+	amount := &params.Amount
+	n := ringBits(len(ring))
+	proof := &MembershipProof{F: make([]big.Int, n), Za: make([]big.Int, n), Zb: make([]big.Int, n)}
+	for _, ps := range []*[]twistededwards.PointAffine{&proof.Cl, &proof.Ca, &proof.Cb, &proof.X, &proof.W, &proof.Q, &proof.B, &proof.P, &proof.Y} {
+		*ps = make([]twistededwards.PointAffine, n)
+	}
+
+	// 位承诺：cl = r·G + σ·h，ca = s·G + a·h，cb = t·G + σa·h
+	nonces, err := randomScalars(rand, 10*n+2)
+	if err != nil {
+		return nil, err
+	}
+	r, s, t, a := nonces[:n], nonces[n:2*n], nonces[2*n:3*n], nonces[3*n:4*n]
+	rho, varsigma, eta, nu, alpha, beta := nonces[4*n:5*n], nonces[5*n:6*n], nonces[6*n:7*n], nonces[7*n:8*n], nonces[8*n:9*n], nonces[9*n:10*n]
+	sigma := make([]*big.Int, n)
+	for j := 0; j < n; j++ {
+		sigma[j] = big.NewInt(int64(bit(w.index, j)))
+		proof.Cl[j] = amount.Commit(sigma[j], r[j])
+		proof.Ca[j] = amount.Commit(a[j], s[j])
+		proof.Cb[j] = amount.Commit(new(big.Int).Mul(sigma[j], a[j]), t[j])
+	}
+
+	// Ê = E_l + β·U，R = β·V + γ·K
+	blindE, blindR := nonces[10*n], nonces[10*n+1]
+	xBlindE := curveutil.ModOrder(new(big.Int).Mul(w.x, blindE))
+	xBlindR := curveutil.ModOrder(new(big.Int).Mul(w.x, blindR))
+	proof.Base = curveutil.KeyImageBase(&ring[w.index].Ota)
+	proof.Base.Add(&proof.Base, new(twistededwards.PointAffine).ScalarMultiplication(&params.U, blindE))
+	proof.Blind = curveutil.MultiScalarMul([]twistededwards.PointAffine{params.V, params.K}, []*big.Int{blindE, blindR})
+
+	// X_k = ρ_k·G + Σ p_{i,k}·M_i，W_k = ς_k·G + Σ p_{i,k}·(C_i − C')，Q_k = η_k·U + Σ p_{i,k}·(Ê − E_i)
+	coeffs := indexPolynomials(len(ring), n, w.index, a)
+	ms := make([]twistededwards.PointAffine, len(ring))
+	for i := range ring {
+		ms[i] = ring[i].Ota
+	}
+	cs := offsets(ring, pseudo)
+	es := keyImageOffsets(ring, &proof.Base)
+	column := make([]*big.Int, len(ring))
+	for k := 0; k < n; k++ {
+		for i := range ring {
+			column[i] = coeffs[i][k]
+		}
+		proof.X[k] = curveutil.MultiScalarMul(append(ms, amount.G), append(column, rho[k]))
+		proof.W[k] = curveutil.MultiScalarMul(append(cs, amount.G), append(column, varsigma[k]))
+		proof.Q[k] = curveutil.MultiScalarMul(append(es, params.U), append(column, eta[k]))
+
+		// B_k = η_k·V + ν_k·K，P_k = ρ_k·R − α_k·V − β_k·K，Y_k = ρ_k·Ê − α_k·U
+		negAlpha := new(big.Int).Neg(alpha[k])
+		proof.B[k] = curveutil.MultiScalarMul([]twistededwards.PointAffine{params.V, params.K}, []*big.Int{eta[k], nu[k]})
+		proof.P[k] = curveutil.MultiScalarMul([]twistededwards.PointAffine{proof.Blind, params.V, params.K}, []*big.Int{rho[k], negAlpha, new(big.Int).Neg(beta[k])})
+		proof.Y[k] = curveutil.MultiScalarMul([]twistededwards.PointAffine{proof.Base, params.U}, []*big.Int{rho[k], negAlpha})
+	}
+
+	x := membershipChallenge(params, ring, tag, pseudo, proof, context)
+	for j := 0; j < n; j++ {
+		// f = σx + a，za = rx + s，zb = r(x − f) + t
+		f := new(big.Int).Mul(sigma[j], x)
+		proof.F[j].Set(curveutil.ModOrder(f.Add(f, a[j])))
+		za := new(big.Int).Mul(r[j], x)
+		proof.Za[j].Set(curveutil.ModOrder(za.Add(za, s[j])))
+		zb := new(big.Int).Sub(x, &proof.F[j])
+		zb.Mul(zb, r[j])
+		proof.Zb[j].Set(curveutil.ModOrder(zb.Add(zb, t[j])))
+	}
+
+	// 各秘密 w 以对应的 ρ 响应 z = w·x^n − Σ ρ_k·x^k
+	xn := powers(x, n+1)
+	respond := func(z *big.Int, w *big.Int, rho []*big.Int) {
+		res := new(big.Int).Mul(w, xn[n])
+		for k := 0; k < n; k++ {
+			res.Sub(res, new(big.Int).Mul(rho[k], xn[k]))
+		}
+		z.Set(curveutil.ModOrder(res))
+	}
+	respond(&proof.Z, w.x, rho)
+	respond(&proof.Zc, w.delta, varsigma)
+	respond(&proof.Ze, blindE, eta)
+	respond(&proof.Zr, blindR, nu)
+	respond(&proof.Zx, xBlindE, alpha)
+	respond(&proof.Zy, xBlindR, beta)
+	return proof, nil
+}
+
+// verifyMembership 对填充后的环 ring 验证成员证明
+func verifyMembership(params *Params, ring []Output, tag, pseudo *twistededwards.PointAffine, proof *MembershipProof, context []byte) bool {
+	amount := &params.Amount
+	n := ringBits(len(ring))
+	for _, ps := range [][]twistededwards.PointAffine{proof.Cl, proof.Ca, proof.Cb, proof.X, proof.W, proof.Q, proof.B, proof.P, proof.Y} {
+		if len(ps) != n {
+			return false
+		}
+	}
+	if len(proof.F) != n || len(proof.Za) != n || len(proof.Zb) != n {
+		return false
+	}
+	x := membershipChallenge(params, ring, tag, pseudo, proof, context)
+
+	// x·cl + ca = f·h + za·G，(x − f)·cl + cb = zb·G
+	for j := 0; j < n; j++ {
+		var lhs, ind twistededwards.PointAffine
+		lhs.ScalarMultiplication(&proof.Cl[j], x)
+		lhs.Add(&lhs, &proof.Ca[j])
+		rhs := amount.Commit(&proof.F[j], &proof.Za[j])
+		if !lhs.Equal(&rhs) {
+			return false
+		}
+		lhs.ScalarMultiplication(&proof.Cl[j], new(big.Int).Sub(x, &proof.F[j]))
+		lhs.Add(&lhs, &proof.Cb[j])
+		ind.ScalarMultiplication(&amount.G, &proof.Zb[j])
+		if !lhs.Equal(&ind) {
+			return false
+		}
+	}
+
+	// t_i = Π_j f_{j,i_j}，其中 f_{j,1} = f_j，f_{j,0} = x − f_j
+	ts := make([]*big.Int, len(ring))
+	for i := range ring {
+		ti := big.NewInt(1)
+		for j := 0; j < n; j++ {
+			if bit(i, j) == 1 {
+				ti.Mul(ti, &proof.F[j])
+			} else {
+				ti.Mul(ti, new(big.Int).Sub(x, &proof.F[j]))
+			}
+			ti = curveutil.ModOrder(ti)
+		}
+		ts[i] = ti
+	}
+	xn := powers(x, n+1)
+	negXn := new(big.Int).Neg(xn[n])
+	negXk := make([]*big.Int, n)
+	for k := 0; k < n; k++ {
+		negXk[k] = new(big.Int).Neg(xn[k])
+	}
+	neg := func(z *big.Int) *big.Int { return new(big.Int).Neg(z) }
+	zero := func(points []twistededwards.PointAffine, scalars ...[]*big.Int) bool {
+		var ks []*big.Int
+		for _, s := range scalars {
+			ks = append(ks, s...)
+		}
+		res := curveutil.MultiScalarMul(points, ks)
+		return res.IsZero()
+	}
+	ms := make([]twistededwards.PointAffine, len(ring))
+	for i := range ring {
+		ms[i] = ring[i].Ota
+	}
+
+	// 一对多等式：Σ t_i·M_i − Σ x^k·X_k − z·G = O，另两式同理
+	if !zero(append(append(ms, proof.X...), amount.G), ts, negXk, []*big.Int{neg(&proof.Z)}) ||
+		!zero(append(append(offsets(ring, pseudo), proof.W...), amount.G), ts, negXk, []*big.Int{neg(&proof.Zc)}) ||
+		!zero(append(append(keyImageOffsets(ring, &proof.Base), proof.Q...), params.U), ts, negXk, []*big.Int{neg(&proof.Ze)}) {
+		return false
+	}
+
+	// 线性等式 Σ z_w·Q_w + Σ x^k·K_k = x^n·P：
+	// R = β·V + γ·K，O = x·R − (xβ)·V − (xγ)·K，J = x·Ê − (xβ)·U
+	return zero(append([]twistededwards.PointAffine{params.V, params.K, proof.Blind}, proof.B...), []*big.Int{&proof.Ze, &proof.Zr, negXn}, xn[:n]) &&
+		zero(append([]twistededwards.PointAffine{proof.Blind, params.V, params.K}, proof.P...), []*big.Int{&proof.Z, neg(&proof.Zx), neg(&proof.Zy)}, xn[:n]) &&
+		zero(append([]twistededwards.PointAffine{proof.Base, params.U, *tag}, proof.Y...), []*big.Int{&proof.Z, neg(&proof.Zx), negXn}, xn[:n])
+}
+
+// indexPolynomials 返回 p_i(x) = Π_j f_{j,i_j}(x) 的低 n 个系数（x^n 项只在 i = l 时为 1，不参与承诺）
+func indexPolynomials(size, n, l int, a []*big.Int) [][]*big.Int {
+	res := make([][]*big.Int, size)
+	for i := 0; i < size; i++ {
+		poly := []*big.Int{big.NewInt(1)}
+		for j := 0; j < n; j++ {
+			// f_{j,1} = σ_j·x + a_j，f_{j,0} = (1 − σ_j)·x − a_j
+			var term [2]*big.Int
+			if bit(i, j) == 1 {
+				term = [2]*big.Int{a[j], big.NewInt(int64(bit(l, j)))}
+			} else {
+				term = [2]*big.Int{new(big.Int).Neg(a[j]), big.NewInt(int64(1 - bit(l, j)))}
+			}
+			poly = mulLinear(poly, term)
+		}
+		res[i] = poly[:n]
+	}
+	return res
+}
+
+// mulLinear 计算 poly·(term[0] + term[1]·x)，系数按升幂排列
+func mulLinear(poly []*big.Int, term [2]*big.Int) []*big.Int {
+	res := make([]*big.Int, len(poly)+1)
+	for i := range res {
+		res[i] = new(big.Int)
+	}
+	for i, c := range poly {
+		res[i].Add(res[i], new(big.Int).Mul(c, term[0]))
+		res[i+1].Add(res[i+1], new(big.Int).Mul(c, term[1]))
+	}
+	for i := range res {
+		res[i] = curveutil.ModOrder(res[i])
+	}
+	return res
+}
+
+// powers 返回 1, x, …, x^{n−1}
+func powers(x *big.Int, n int) []*big.Int {
+	res := make([]*big.Int, n)
+	res[0] = big.NewInt(1)
+	for i := 1; i < n; i++ {
+		res[i] = curveutil.ModOrder(new(big.Int).Mul(res[i-1], x))
+	}
+	return res
+}
+
+func randomScalars(rand io.Reader, n int) ([]*big.Int, error) {
+	res := make([]*big.Int, n)
+	for i := range res {
+		k, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = k
+	}
+	return res, nil
+}
+
+// membershipChallenge 计算 H(G, h, U, V, K, 环, J, C', Ê, R, context, 各承诺) mod order
+func membershipChallenge(params *Params, ring []Output, tag, pseudo *twistededwards.PointAffine, proof *MembershipProof, context []byte) *big.Int {
+	data := [][]byte{params.Amount.G.Marshal(), params.Amount.H.Marshal(), params.U.Marshal(), params.V.Marshal(), params.K.Marshal()}
+	for i := range ring {
+		data = append(data, ring[i].Ota.Marshal(), ring[i].Commitment.Marshal())
+	}
+	data = append(data, tag.Marshal(), pseudo.Marshal(), proof.Base.Marshal(), proof.Blind.Marshal(), context)
+	for _, ps := range [][]twistededwards.PointAffine{proof.Cl, proof.Ca, proof.Cb, proof.X, proof.W, proof.Q, proof.B, proof.P, proof.Y} {
+		for i := range ps {
+			data = append(data, ps[i].Marshal())
+		}
+	}
+	return curveutil.HashToScalar(membershipDomain, data...)
+}
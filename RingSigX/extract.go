@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+var errExtraction = errors.New("ringsig: transcripts do not yield a witness")
+
+// extractRing 特殊可靠性提取器：由第一轮消息相同、挑战互不相同的 n+1 个被接受副本求出
+// 签名者下标 l、私钥 sk、监管密文随机数 u 与协议 3 中的 m
+func extractRing(params *ringParams, st *ringStatement, com *ringCommitment, xs []*big.Int, resps []*ringResponse) (*ringWitness, *big.Int, error) {
+	n := st.n
+	if len(xs) != n+1 || len(resps) != n+1 {
+		return nil, nil, errExtraction
+	}
+	for i := range xs {
+		if verifyRing(params, st, com, xs[i], resps[i]) != nil {
+			return nil, nil, errExtraction
+		}
+	}
+
+	// f_j = b_j·x + a_j，两个副本即可解出 b_j；getBit 的第 1 位是最高位
+	curve := twistededwards.GetEdwardsCurve()
+	inv := new(big.Int).ModInverse(curveutil.ModOrder(new(big.Int).Sub(xs[0], xs[1])), &curve.Order)
+	if inv == nil {
+		return nil, nil, errExtraction
+	}
+	l := 0
+	for j := 0; j < n; j++ {
+		b := new(big.Int).Sub(&resps[0].f[j], &resps[1].f[j])
+		b = curveutil.ModOrder(b.Mul(b, inv))
+		if b.Cmp(big.NewInt(1)) > 0 {
+			return nil, nil, errExtraction
+		}
+		l = l<<1 | int(b.Int64())
+	}
+	if l >= len(st.pks) {
+		return nil, nil, errExtraction
+	}
+
+	// zd(x) = sk·x^n − Σ ρ_k·x^k 是 n 次多项式，n+1 个点插值得到首项系数 sk；zd3 同理得到 u
+	zd := make([]*big.Int, n+1)
+	zd3 := make([]*big.Int, n+1)
+	for i := range resps {
+		zd[i], zd3[i] = &resps[i].zd, &resps[i].zd3
+	}
+	sk, err := leadingCoefficient(xs, zd)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := leadingCoefficient(xs, zd3)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipher := cipherRelation(params.G, params.h, st.C1)
+	trs := make([]*sigmaproof.Transcript, 2)
+	for i := range trs {
+		trs[i] = &sigmaproof.Transcript{Commitments: com.cipher, Z: resps[i].cipher}
+		trs[i].C.Set(xs[i])
+	}
+	cw, err := cipher.Extract(trs[0], trs[1])
+	if err != nil {
+		return nil, nil, errExtraction
+	}
+
+	w := &ringWitness{l: l, sk: sk, u: u}
+	var pk, tag, C1, C2 twistededwards.PointAffine
+	pk.ScalarMultiplication(&params.G, sk)
+	tag.ScalarMultiplication(&st.E, sk)
+	C1.ScalarMultiplication(&params.h, cw[1])
+	C1.Add(&C1, new(twistededwards.PointAffine).ScalarMultiplication(&params.G, u))
+	C2.ScalarMultiplication(&params.pkRev, u)
+	C2.Add(&C2, &pk)
+	if !pk.Equal(&st.pks[l]) || !tag.Equal(&st.T) || !C1.Equal(&st.C1) || !C2.Equal(&st.C2) {
+		return nil, nil, errExtraction
+	}
+	return w, cw[1], nil
+}
+
+// leadingCoefficient 由 n+1 个点 (x_i, y_i) 插值 n 次多项式，返回首项系数 Σ y_i / Π_{j≠i}(x_i − x_j)
+func leadingCoefficient(xs, ys []*big.Int) (*big.Int, error) {
+	curve := twistededwards.GetEdwardsCurve()
+	res := new(big.Int)
+	for i := range xs {
+		den := big.NewInt(1)
+		for j := range xs {
+			if j != i {
+				den = curveutil.ModOrder(den.Mul(den, new(big.Int).Sub(xs[i], xs[j])))
+			}
+		}
+		if den.ModInverse(den, &curve.Order) == nil {
+			return nil, errExtraction
+		}
+		res.Add(res, den.Mul(den, ys[i]))
+	}
+	return curveutil.ModOrder(res), nil
+}
+
+// rewindRing 把签名者当作黑盒回卷：一次 commit 之后对 n+1 个随机挑战各 respond 一次，再用 extractRing 求出证据
+func rewindRing(rand io.Reader, params *ringParams, st *ringStatement, w *ringWitness) (*ringWitness, *big.Int, error) {
+	p, err := newRingProver(rand, params, st, w)
+	if err != nil {
+		return nil, nil, err
+	}
+	com := p.commit()
+	xs, err := randomScalars(rand, st.n+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	resps := make([]*ringResponse, len(xs))
+	for i := range xs {
+		resps[i] = p.respond(xs[i])
+	}
+	return extractRing(params, st, com, xs, resps)
+}
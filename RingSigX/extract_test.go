@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// 对不同环大小与每个签名者位置，回卷 n+1 次后提取出的 (l, sk, u, m) 必须与证据一致
+func TestRewindRingExtractsWitness(t *testing.T) {
+	for n := 1; n <= 3; n++ {
+		for l := 0; l < 1<<n; l++ {
+			params, st, w, _ := newTestRing(t, n, l)
+			got, m, err := rewindRing(rand.Reader, params, st, w)
+			if err != nil {
+				t.Fatalf("n=%d l=%d: %v", n, l, err)
+			}
+			if got.l != w.l || got.sk.Cmp(w.sk) != 0 || got.u.Cmp(w.u) != 0 || m.Sign() != 0 {
+				t.Fatalf("n=%d l=%d: extracted (%d, %v, %v, %v)", n, l, got.l, got.sk, got.u, m)
+			}
+		}
+	}
+}
+
+func TestExtractRingRejects(t *testing.T) {
+	const n = 2
+	params, st, w, _ := newTestRing(t, n, 1)
+	p, err := newRingProver(rand.Reader, params, st, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	com := p.commit()
+	xs, err := randomScalars(rand.Reader, n+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resps := make([]*ringResponse, len(xs))
+	for i := range xs {
+		resps[i] = p.respond(xs[i])
+	}
+
+	// 只有 n 个副本不足以确定 n 次多项式的首项系数
+	if _, _, err := extractRing(params, st, com, xs[:n], resps[:n]); !errors.Is(err, errExtraction) {
+		t.Fatalf("n transcripts: got %v, want errExtraction", err)
+	}
+
+	// n+1 个副本中有两个挑战相同
+	dupXs := append([]*big.Int{xs[0]}, xs[:n]...)
+	dupResps := append([]*ringResponse{resps[0]}, resps[:n]...)
+	if _, _, err := extractRing(params, st, com, dupXs, dupResps); !errors.Is(err, errExtraction) {
+		t.Fatalf("duplicate challenge: got %v, want errExtraction", err)
+	}
+
+	// 挑战与响应不对应
+	swapped := append([]*ringResponse{resps[1], resps[0]}, resps[2:]...)
+	if _, _, err := extractRing(params, st, com, xs, swapped); !errors.Is(err, errExtraction) {
+		t.Fatalf("rejected transcript: got %v, want errExtraction", err)
+	}
+
+	if _, _, err := extractRing(params, st, com, xs, resps); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// ringDomain 环签名 Fiat-Shamir 挑战的域分隔标签
+const ringDomain = "LYcode/RingSigX/v1"
+
+var (
+	errBits      = errors.New("ringsig: bit commitments do not verify")
+	errProtocol1 = errors.New("ringsig: protocol 1 (ring membership) does not verify")
+	errProtocol2 = errors.New("ringsig: protocol 2 (linkable tag) does not verify")
+	errProtocol3 = errors.New("ringsig: protocol 3 (regulator ciphertext) does not verify")
+	errWitness   = errors.New("ringsig: witness does not match statement")
+)
+
+// ringParams 公共参数：基点 G、h 与监管方公钥 pk_rev
+type ringParams struct {
+	G, h, pkRev twistededwards.PointAffine
+}
+
+// ringStatement 陈述：环中公钥（共 2^n 个）、一次性标志 T = sk·E 与监管密文 (C1, C2) = (u·G, u·pk_rev + pk_l)
+type ringStatement struct {
+	pks    []twistededwards.PointAffine
+	n      int
+	E, T   twistededwards.PointAffine
+	C1, C2 twistededwards.PointAffine
+}
+
+// ringWitness 证据：签名者下标 l、私钥 sk 与监管密文随机数 u
+type ringWitness struct {
+	l     int
+	sk, u *big.Int
+}
+
+// ringCommitment 证明者的第一轮消息
+type ringCommitment struct {
+	cl, ca, cb []twistededwards.PointAffine // 下标各位的承诺
+	cd         []twistededwards.PointAffine // 协议 1：pk_l 在环中
+	cd2        []twistededwards.PointAffine // 协议 2：T 与 pk_l 的私钥一致
+	cd3        []twistededwards.PointAffine // 协议 3：C2 − pk_l = u·pk_rev
+	cipher     []twistededwards.PointAffine // 协议 3：C1 = u·G + m·h，m ∈ {0, 1}
+}
+
+// ringResponse 证明者对挑战 x 的响应
+type ringResponse struct {
+	f, za, zb []big.Int
+	zd, zd3   big.Int
+	cipher    []big.Int
+}
+
+// ringProver 交互式证明者，每个实例只能使用一次
+type ringProver struct {
+	params *ringParams
+	st     *ringStatement
+	w      *ringWitness
+
+	r, a, s, t []*big.Int
+	rho, rho3  []*big.Int
+	cipher     *sigmaproof.Prover
+}
+
+// cipherRelation 协议 3 中密文 C1 的知识证明，以 (u, m, w) 为秘密标量：
+//
+//	C1 = u·G + m·h，C1 = m·C1 + w·G
+//
+// 第二式在 m ∈ {0, 1} 时成立（w = (1 − m)·u），对应原协议中 l0/l1 两个等式
+func cipherRelation(G, h, C1 twistededwards.PointAffine) *sigmaproof.Relation {
+	r := sigmaproof.New("LYcode/RingSigX/Protocol3/v1")
+	pG, ph, pC1 := r.Point(G), r.Point(h), r.Point(C1)
+	u, m, w := r.Scalar(), r.Scalar(), r.Scalar()
+	r.Equation(pC1, sigmaproof.Term{X: u, P: pG}, sigmaproof.Term{X: m, P: ph})
+	r.Equation(pC1, sigmaproof.Term{X: m, P: pC1}, sigmaproof.Term{X: w, P: pG})
+	return r
+}
+
+// offsets 返回协议 3 中的 c_i = C2 − pk_i
+func (st *ringStatement) offsets() []twistededwards.PointAffine {
+	res := make([]twistededwards.PointAffine, len(st.pks))
+	for i := range st.pks {
+		res[i].Neg(&st.pks[i])
+		res[i].Add(&st.C2, &res[i])
+	}
+	return res
+}
+
+func newRingProver(rand io.Reader, params *ringParams, st *ringStatement, w *ringWitness) (*ringProver, error) {
+	if w.l < 0 || w.l >= len(st.pks) {
+		return nil, errWitness
+	}
+	var pk twistededwards.PointAffine
+	pk.ScalarMultiplication(&params.G, w.sk)
+	if !pk.Equal(&st.pks[w.l]) {
+		return nil, errWitness
+	}
+	// 协议 3 中 m = 0，w = u
+	cipher, err := cipherRelation(params.G, params.h, st.C1).NewProver(rand, []*big.Int{w.u, big.NewInt(0), w.u})
+	if err != nil {
+		return nil, errWitness
+	}
+	p := &ringProver{params: params, st: st, w: w, cipher: cipher}
+	for _, v := range []*[]*big.Int{&p.r, &p.a, &p.s, &p.t, &p.rho, &p.rho3} {
+		if *v, err = randomScalars(rand, st.n); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// commit 计算第一轮消息。协议 3 使用独立的 ρ'，
+// 与协议 1 共用 ρ 会使 zd − zd3 = (sk − u)·x^n 泄露签名者
+func (p *ringProver) commit() *ringCommitment {
+	params, st, n := p.params, p.st, p.st.n
+	com := &ringCommitment{
+		cl: make([]twistededwards.PointAffine, n),
+		ca: make([]twistededwards.PointAffine, n),
+		cb: make([]twistededwards.PointAffine, n),
+	}
+	var ind twistededwards.PointAffine
+	for j := 0; j < n; j++ {
+		// cl = r·G + b·h，ca = s·G + a·h，cb = t·G + b·a·h
+		com.cl[j].ScalarMultiplication(&params.G, p.r[j])
+		com.ca[j].ScalarMultiplication(&params.h, p.a[j])
+		ind.ScalarMultiplication(&params.G, p.s[j])
+		com.ca[j].Add(&com.ca[j], &ind)
+		com.cb[j].ScalarMultiplication(&params.G, p.t[j])
+		if getBit(p.w.l, n, j+1) == 1 {
+			com.cl[j].Add(&com.cl[j], &params.h)
+			ind.ScalarMultiplication(&params.h, p.a[j])
+			com.cb[j].Add(&com.cb[j], &ind)
+		}
+	}
+
+	// cd_k = ρ_k·G + Σ p_{i,k}·pk_i，cd2_k = ρ_k·E + Σ p_{i,k}·T，cd3_k = ρ'_k·pk_rev + Σ p_{i,k}·c_i
+	pik := GetPik(len(st.pks), n, p.w.l, p.a)
+	com.cd = coefficientCommitments(pik, st.pks, p.rho, &params.G)
+	tags := make([]twistededwards.PointAffine, len(st.pks))
+	for i := range tags {
+		tags[i] = st.T
+	}
+	com.cd2 = coefficientCommitments(pik, tags, p.rho, &st.E)
+	com.cd3 = coefficientCommitments(pik, st.offsets(), p.rho3, &params.pkRev)
+	com.cipher = p.cipher.Commit()
+	return com
+}
+
+// coefficientCommitments 计算 ρ_k·B + Σ_i p_{i,k}·P_i
+func coefficientCommitments(pik [][]*big.Int, points []twistededwards.PointAffine, rho []*big.Int, B *twistededwards.PointAffine) []twistededwards.PointAffine {
+	res := make([]twistededwards.PointAffine, len(rho))
+	column := make([]*big.Int, len(points))
+	for k := range rho {
+		for i := range points {
+			column[i] = pik[i][k]
+		}
+		var ind twistededwards.PointAffine
+		res[k] = curveutil.MultiScalarMul(points, column)
+		ind.ScalarMultiplication(B, rho[k])
+		res[k].Add(&res[k], &ind)
+	}
+	return res
+}
+
+// respond 计算对挑战 x 的响应
+func (p *ringProver) respond(x *big.Int) *ringResponse {
+	n := p.st.n
+	x = curveutil.ModOrder(x)
+	resp := &ringResponse{f: make([]big.Int, n), za: make([]big.Int, n), zb: make([]big.Int, n)}
+	for j := 0; j < n; j++ {
+		// f = b·x + a，za = r·x + s，zb = r·(x − f) + t
+		f := new(big.Int).Set(p.a[j])
+		if getBit(p.w.l, n, j+1) == 1 {
+			f.Add(f, x)
+		}
+		resp.f[j].Set(curveutil.ModOrder(f))
+		za := new(big.Int).Mul(p.r[j], x)
+		resp.za[j].Set(curveutil.ModOrder(za.Add(za, p.s[j])))
+		zb := new(big.Int).Sub(x, &resp.f[j])
+		zb.Mul(zb, p.r[j])
+		resp.zb[j].Set(curveutil.ModOrder(zb.Add(zb, p.t[j])))
+	}
+
+	// zd = sk·x^n − Σ ρ_k·x^k，zd3 = u·x^n − Σ ρ'_k·x^k
+	xk := xPowers(x, n+1)
+	zd := new(big.Int).Mul(p.w.sk, xk[n])
+	zd3 := new(big.Int).Mul(p.w.u, xk[n])
+	for k := 0; k < n; k++ {
+		zd.Sub(zd, new(big.Int).Mul(p.rho[k], xk[k]))
+		zd3.Sub(zd3, new(big.Int).Mul(p.rho3[k], xk[k]))
+	}
+	resp.zd.Set(curveutil.ModOrder(zd))
+	resp.zd3.Set(curveutil.ModOrder(zd3))
+	resp.cipher = p.cipher.Respond(x)
+	return resp
+}
+
+// verifyRing 检查 (com, x, resp) 满足全部验证方程
+func verifyRing(params *ringParams, st *ringStatement, com *ringCommitment, x *big.Int, resp *ringResponse) error {
+	n := st.n
+	if len(com.cl) != n || len(com.ca) != n || len(com.cb) != n || len(com.cd) != n || len(com.cd2) != n || len(com.cd3) != n ||
+		len(resp.f) != n || len(resp.za) != n || len(resp.zb) != n {
+		return errBits
+	}
+	x = curveutil.ModOrder(x)
+
+	// x·cl + ca = f·h + za·G，(x − f)·cl + cb = zb·G
+	for j := 0; j < n; j++ {
+		var lhs, rhs, ind twistededwards.PointAffine
+		lhs.ScalarMultiplication(&com.cl[j], x)
+		lhs.Add(&lhs, &com.ca[j])
+		rhs.ScalarMultiplication(&params.h, &resp.f[j])
+		ind.ScalarMultiplication(&params.G, &resp.za[j])
+		rhs.Add(&rhs, &ind)
+		if !lhs.Equal(&rhs) {
+			return errBits
+		}
+		lhs.ScalarMultiplication(&com.cl[j], new(big.Int).Sub(x, &resp.f[j]))
+		lhs.Add(&lhs, &com.cb[j])
+		rhs.ScalarMultiplication(&params.G, &resp.zb[j])
+		if !lhs.Equal(&rhs) {
+			return errBits
+		}
+	}
+
+	ts := indexProducts(len(st.pks), n, x, resp.f)
+	xk := xPowers(x, n+1)
+	// Σ t_i·pk_i − Σ x^k·cd_k = zd·G
+	if !coefficientsOpen(st.pks, ts, com.cd, xk, &resp.zd, &params.G) {
+		return errProtocol1
+	}
+	// Σ t_i·T − Σ x^k·cd2_k = zd·E（Σ t_i = x^n）
+	if !coefficientsOpen([]twistededwards.PointAffine{st.T}, []*big.Int{xk[n]}, com.cd2, xk, &resp.zd, &st.E) {
+		return errProtocol2
+	}
+	// Σ t_i·c_i − Σ x^k·cd3_k = zd3·pk_rev
+	if !coefficientsOpen(st.offsets(), ts, com.cd3, xk, &resp.zd3, &params.pkRev) {
+		return errProtocol3
+	}
+	tr := &sigmaproof.Transcript{Commitments: com.cipher, Z: resp.cipher}
+	tr.C.Set(x)
+	if !cipherRelation(params.G, params.h, st.C1).Accepts(tr) {
+		return errProtocol3
+	}
+	return nil
+}
+
+// coefficientsOpen 检查 Σ t_i·P_i − Σ x^k·D_k − z·B = O
+func coefficientsOpen(points []twistededwards.PointAffine, ts []*big.Int, D []twistededwards.PointAffine, xk []*big.Int, z *big.Int, B *twistededwards.PointAffine) bool {
+	ps := append(append(append([]twistededwards.PointAffine(nil), points...), D...), *B)
+	ks := append([]*big.Int(nil), ts...)
+	for k := range D {
+		ks = append(ks, new(big.Int).Neg(xk[k]))
+	}
+	ks = append(ks, new(big.Int).Neg(z))
+	res := curveutil.MultiScalarMul(ps, ks)
+	return res.IsZero()
+}
+
+// indexProducts 计算 t_i = Π_j f_{j,i_j}，f_{j,1} = f_j，f_{j,0} = x − f_j
+func indexProducts(N, n int, x *big.Int, f []big.Int) []*big.Int {
+	ts := make([]*big.Int, N)
+	for i := range ts {
+		ti := big.NewInt(1)
+		for j := 0; j < n; j++ {
+			if getBit(i, n, j+1) == 1 {
+				ti.Mul(ti, &f[j])
+			} else {
+				ti.Mul(ti, new(big.Int).Sub(x, &f[j]))
+			}
+			ti = curveutil.ModOrder(ti)
+		}
+		ts[i] = ti
+	}
+	return ts
+}
+
+// simulateRing 不用证据，对挑战 x 生成可被接受的副本：位承诺由 f、za、zb 与随机的 cl 反解，
+// cd_k、cd2_k、cd3_k（k ≥ 1）取 ρ_k·G、ρ_k·E、ρ'_k·pk_rev，再由验证方程反解 k = 0 项
+func simulateRing(rand io.Reader, params *ringParams, st *ringStatement, x *big.Int) (*ringCommitment, *ringResponse, error) {
+	n := st.n
+	x = curveutil.ModOrder(x)
+	nonces, err := randomScalars(rand, 6*n+2)
+	if err != nil {
+		return nil, nil, err
+	}
+	com := &ringCommitment{
+		cl: make([]twistededwards.PointAffine, n),
+		ca: make([]twistededwards.PointAffine, n),
+		cb: make([]twistededwards.PointAffine, n),
+	}
+	resp := &ringResponse{f: make([]big.Int, n), za: make([]big.Int, n), zb: make([]big.Int, n)}
+	for j := 0; j < n; j++ {
+		resp.f[j].Set(nonces[j])
+		resp.za[j].Set(nonces[n+j])
+		resp.zb[j].Set(nonces[2*n+j])
+		com.cl[j].ScalarMultiplication(&params.G, nonces[3*n+j])
+
+		// ca = f·h + za·G − x·cl，cb = zb·G − (x − f)·cl
+		var ind twistededwards.PointAffine
+		com.ca[j].ScalarMultiplication(&params.h, &resp.f[j])
+		ind.ScalarMultiplication(&params.G, &resp.za[j])
+		com.ca[j].Add(&com.ca[j], &ind)
+		ind.ScalarMultiplication(&com.cl[j], new(big.Int).Neg(x))
+		com.ca[j].Add(&com.ca[j], &ind)
+		com.cb[j].ScalarMultiplication(&params.G, &resp.zb[j])
+		ind.ScalarMultiplication(&com.cl[j], new(big.Int).Sub(&resp.f[j], x))
+		com.cb[j].Add(&com.cb[j], &ind)
+	}
+	resp.zd.Set(nonces[6*n])
+	resp.zd3.Set(nonces[6*n+1])
+
+	ts := indexProducts(len(st.pks), n, x, resp.f)
+	xk := xPowers(x, n+1)
+	rho, rho3 := nonces[4*n:5*n], nonces[5*n:6*n]
+	com.cd = simulateCoefficients(st.pks, ts, rho, &params.G, xk, &resp.zd)
+	com.cd2 = simulateCoefficients([]twistededwards.PointAffine{st.T}, []*big.Int{xk[n]}, rho, &st.E, xk, &resp.zd)
+	com.cd3 = simulateCoefficients(st.offsets(), ts, rho3, &params.pkRev, xk, &resp.zd3)
+
+	tr, err := cipherRelation(params.G, params.h, st.C1).Simulate(rand, x)
+	if err != nil {
+		return nil, nil, err
+	}
+	com.cipher, resp.cipher = tr.Commitments, tr.Z
+	return com, resp, nil
+}
+
+// simulateCoefficients 取 D_k = ρ_k·B（k ≥ 1），D_0 = Σ t_i·P_i − Σ_{k≥1} x^k·D_k − z·B
+func simulateCoefficients(points []twistededwards.PointAffine, ts, rho []*big.Int, B *twistededwards.PointAffine, xk []*big.Int, z *big.Int) []twistededwards.PointAffine {
+	D := make([]twistededwards.PointAffine, len(rho))
+	ps := append([]twistededwards.PointAffine(nil), points...)
+	ks := append([]*big.Int(nil), ts...)
+	for k := 1; k < len(rho); k++ {
+		D[k].ScalarMultiplication(B, rho[k])
+		ps = append(ps, D[k])
+		ks = append(ks, new(big.Int).Neg(xk[k]))
+	}
+	ps = append(ps, *B)
+	ks = append(ks, new(big.Int).Neg(z))
+	D[0] = curveutil.MultiScalarMul(ps, ks)
+	return D
+}
+
+// ringChallenge Fiat-Shamir 挑战 H(G, h, pk_rev, 环, E, T, C1, C2, 第一轮消息) mod order
+func ringChallenge(params *ringParams, st *ringStatement, com *ringCommitment, message []byte) *big.Int {
+	data := [][]byte{params.G.Marshal(), params.h.Marshal(), params.pkRev.Marshal()}
+	for i := range st.pks {
+		data = append(data, st.pks[i].Marshal())
+	}
+	data = append(data, st.E.Marshal(), st.T.Marshal(), st.C1.Marshal(), st.C2.Marshal(), message)
+	for _, ps := range com.groups() {
+		for i := range *ps {
+			data = append(data, (*ps)[i].Marshal())
+		}
+	}
+	return curveutil.HashToScalar(ringDomain, data...)
+}
+
+// ringSignature 非交互环签名：第一轮消息与响应，挑战由二者重算
+type ringSignature struct {
+	com  *ringCommitment
+	resp *ringResponse
+}
+
+// signRing 对 message 生成环签名
+func signRing(rand io.Reader, params *ringParams, st *ringStatement, w *ringWitness, message []byte) (*ringSignature, error) {
+	p, err := newRingProver(rand, params, st, w)
+	if err != nil {
+		return nil, err
+	}
+	com := p.commit()
+	return &ringSignature{com: com, resp: p.respond(ringChallenge(params, st, com, message))}, nil
+}
+
+// verifyRingSignature 验证环签名
+func verifyRingSignature(params *ringParams, st *ringStatement, sig *ringSignature, message []byte) error {
+	return verifyRing(params, st, sig.com, ringChallenge(params, st, sig.com, message), sig.resp)
+}
+
+// components 返回副本各分量的编码，供 sigmaproof.CompareSamplers 使用
+func (com *ringCommitment) components(x *big.Int, resp *ringResponse) [][]byte {
+	var res [][]byte
+	for _, ps := range com.groups() {
+		for i := range *ps {
+			res = append(res, (*ps)[i].Marshal())
+		}
+	}
+	xb := curveutil.ScalarBytes(x)
+	res = append(res, xb[:])
+	for _, ks := range [][]big.Int{resp.f, resp.za, resp.zb, {resp.zd, resp.zd3}, resp.cipher} {
+		for i := range ks {
+			b := curveutil.ScalarBytes(&ks[i])
+			res = append(res, b[:])
+		}
+	}
+	return res
+}
+
+// xPowers 返回 1, x, …, x^{n−1} mod order
+func xPowers(x *big.Int, n int) []*big.Int {
+	res := make([]*big.Int, n)
+	res[0] = big.NewInt(1)
+	for i := 1; i < n; i++ {
+		res[i] = curveutil.ModOrder(new(big.Int).Mul(res[i-1], x))
+	}
+	return res
+}
+
+func randomScalars(rand io.Reader, n int) ([]*big.Int, error) {
+	res := make([]*big.Int, n)
+	for i := range res {
+		k, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = k
+	}
+	return res, nil
+}
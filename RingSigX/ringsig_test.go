@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/ConfAmount"
+	"MissionYang/ReserveProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// newTestRing 构造大小为 2^n 的环与签名者 l 的陈述和证据，构造方式与 main 相同
+func newTestRing(t *testing.T, n, l int) (*ringParams, *ringStatement, *ringWitness, []User) {
+	t.Helper()
+	curve := twistededwards.GetEdwardsCurve()
+	users := make([]User, 1<<n)
+	for i := range users {
+		user, err := getUser(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		users[i] = user
+	}
+	rev, err := getUser(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := randomGenerator(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &ringParams{G: curve.Base, h: h, pkRev: rev.pk}
+
+	st := &ringStatement{pks: make([]twistededwards.PointAffine, len(users)), n: n}
+	for i := range users {
+		st.pks[i] = users[i].pk
+	}
+	st.E = curveutil.KeyImageBase(&users[l].pk)
+	st.T.ScalarMultiplication(&st.E, &users[l].sk)
+	u, err := curveutil.RandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.C1.ScalarMultiplication(&params.G, u)
+	st.C2.ScalarMultiplication(&params.pkRev, u)
+	st.C2.Add(&st.C2, &users[l].pk)
+	return params, st, &ringWitness{l: l, sk: &users[l].sk, u: u}, users
+}
+
+// 花费时环签名公开的一次性标志 T 必须等于储备证明为同一输出给出的链接标签
+func TestKeyImageMatchesReserveTag(t *testing.T) {
+	const l = 2
+	params, st, w, users := newTestRing(t, 2, l)
+	msg := []byte("spend")
+	sig, err := signRing(rand.Reader, params, st, w, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyRingSignature(params, st, sig, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	amountParams := confamount.DefaultParams()
+	reserveParams := reserveproof.NewParams(amountParams)
+	ring := make([]reserveproof.Output, len(users))
+	var key reserveproof.Key
+	for i := range users {
+		m := uint64(100 * (i + 1))
+		r, err := curveutil.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring[i] = reserveproof.Output{Ota: users[i].pk, Commitment: amountParams.Commit(new(big.Int).SetUint64(m), r)}
+		if i == l {
+			key = reserveproof.Key{Index: i, X: &users[i].sk, M: m, R: r}
+		}
+	}
+	context := []byte("height-1")
+	proof, err := reserveproof.Prove(rand.Reader, reserveParams, ring, []reserveproof.Key{key}, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := proof.Outputs[0].Tag; !tag.Equal(&st.T) {
+		t.Fatal("reserve tag differs from the ring signature key image")
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded reserveproof.ReserveProof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := reserveproof.Verify(reserveParams, ring, &decoded, nil, context); err != nil {
+		t.Fatal(err)
+	}
+	spent := map[twistededwards.PointAffine]bool{st.T: true}
+	if err := reserveproof.Verify(reserveParams, ring, proof, spent, context); !errors.Is(err, reserveproof.ErrSpentOutput) {
+		t.Fatalf("got %v, want ErrSpentOutput", err)
+	}
+
+	// 换成别的标签（例如另一个环成员的标志）后证明不再成立
+	forged := *proof
+	forged.Outputs = append([]reserveproof.OwnedOutput(nil), proof.Outputs...)
+	other := curveutil.KeyImageBase(&users[0].pk)
+	forged.Outputs[0].Tag.ScalarMultiplication(&other, &users[0].sk)
+	if err := reserveproof.Verify(reserveParams, ring, &forged, nil, context); !errors.Is(err, reserveproof.ErrInvalidReserve) {
+		t.Fatalf("got %v, want ErrInvalidReserve", err)
+	}
+}
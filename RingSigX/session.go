@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"net"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// cipherCommitments cipherRelation 中的等式个数，cipherResponses 为其秘密标量个数
+const (
+	cipherCommitments = 2
+	cipherResponses   = 3
+)
+
+var errEncoding = errors.New("ringsig: malformed message")
+
+// groups 按固定顺序列出第一轮消息中的各组点
+func (com *ringCommitment) groups() []*[]twistededwards.PointAffine {
+	return []*[]twistededwards.PointAffine{&com.cl, &com.ca, &com.cb, &com.cd, &com.cd2, &com.cd3, &com.cipher}
+}
+
+// MarshalBinary 序列化为各组点的压缩形式依次拼接，组大小由 n 确定
+func (com *ringCommitment) MarshalBinary() ([]byte, error) {
+	var res []byte
+	for _, ps := range com.groups() {
+		for i := range *ps {
+			b := (*ps)[i].Bytes()
+			res = append(res, b[:]...)
+		}
+	}
+	return res, nil
+}
+
+// unmarshalRingCommitment 从 MarshalBinary 的输出恢复环大小为 2^n 的第一轮消息
+func unmarshalRingCommitment(n int, data []byte) (*ringCommitment, error) {
+	if len(data) != (6*n+cipherCommitments)*curveutil.PointSize {
+		return nil, errEncoding
+	}
+	com := new(ringCommitment)
+	off := 0
+	for g, ps := range com.groups() {
+		size := n
+		if g == len(com.groups())-1 {
+			size = cipherCommitments
+		}
+		*ps = make([]twistededwards.PointAffine, size)
+		for i := range *ps {
+			p, err := curveutil.PointFromBytes(data[off : off+curveutil.PointSize])
+			if err != nil {
+				return nil, errEncoding
+			}
+			(*ps)[i] = p
+			off += curveutil.PointSize
+		}
+	}
+	return com, nil
+}
+
+// MarshalBinary 序列化为 f || za || zb || zd || zd3 || cipher 响应
+func (resp *ringResponse) MarshalBinary() ([]byte, error) {
+	var res []byte
+	for _, ks := range [][]big.Int{resp.f, resp.za, resp.zb, {resp.zd, resp.zd3}, resp.cipher} {
+		for i := range ks {
+			b := curveutil.ScalarBytes(&ks[i])
+			res = append(res, b[:]...)
+		}
+	}
+	return res, nil
+}
+
+// unmarshalRingResponse 从 MarshalBinary 的输出恢复环大小为 2^n 的响应
+func unmarshalRingResponse(n int, data []byte) (*ringResponse, error) {
+	if len(data) != (3*n+2+cipherResponses)*curveutil.ScalarSize {
+		return nil, errEncoding
+	}
+	ks := make([]big.Int, len(data)/curveutil.ScalarSize)
+	for i := range ks {
+		k, err := curveutil.ScalarFromBytes(data[i*curveutil.ScalarSize : (i+1)*curveutil.ScalarSize])
+		if err != nil {
+			return nil, errEncoding
+		}
+		ks[i].Set(k)
+	}
+	resp := &ringResponse{f: ks[:n], za: ks[n : 2*n], zb: ks[2*n : 3*n], cipher: ks[3*n+2:]}
+	resp.zd.Set(&ks[3*n])
+	resp.zd3.Set(&ks[3*n+1])
+	return resp, nil
+}
+
+// ringInteractiveProver 以 sigmaproof.InteractiveProver 的形式包装 ringProver
+type ringInteractiveProver struct{ p *ringProver }
+
+func (rp ringInteractiveProver) Commit() ([]byte, error) {
+	return rp.p.commit().MarshalBinary()
+}
+
+func (rp ringInteractiveProver) Respond(x *big.Int) ([]byte, error) {
+	return rp.p.respond(x).MarshalBinary()
+}
+
+// ringInteractiveVerifier 以 sigmaproof.InteractiveVerifier 的形式包装 verifyRing
+type ringInteractiveVerifier struct {
+	params *ringParams
+	st     *ringStatement
+}
+
+func (rv ringInteractiveVerifier) Check(commitment []byte, x *big.Int, response []byte) error {
+	com, err := unmarshalRingCommitment(rv.st.n, commitment)
+	if err != nil {
+		return err
+	}
+	resp, err := unmarshalRingResponse(rv.st.n, response)
+	if err != nil {
+		return err
+	}
+	return verifyRing(rv.params, rv.st, com, x, resp)
+}
+
+// proveRingInteractive 在 conn 上以交互方式执行环签名协议（挑战由验证者随机选取，不经过 Fiat-Shamir）
+func proveRingInteractive(rand io.Reader, conn io.ReadWriter, params *ringParams, st *ringStatement, w *ringWitness) error {
+	p, err := newRingProver(rand, params, st, w)
+	if err != nil {
+		return err
+	}
+	return sigmaproof.NewProverSession(conn, ringInteractiveProver{p}).Run()
+}
+
+// verifyRingInteractive 在 conn 上作为验证者执行环签名协议
+func verifyRingInteractive(conn io.ReadWriter, params *ringParams, st *ringStatement) error {
+	return sigmaproof.NewVerifierSession(conn, ringInteractiveVerifier{params: params, st: st}).Run()
+}
+
+// runRingSession 在一对连接上并发执行证明者与验证者，返回验证者的结论
+func runRingSession(rand io.Reader, proverConn, verifierConn net.Conn, params *ringParams, st *ringStatement, w *ringWitness) error {
+	errc := make(chan error, 1)
+	go func() {
+		defer proverConn.Close()
+		errc <- proveRingInteractive(rand, proverConn, params, st, w)
+	}()
+	err := verifyRingInteractive(verifierConn, params, st)
+	verifierConn.Close()
+	if perr := <-errc; err == nil {
+		err = perr
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/conntest"
+)
+
+// newRingSessions 为签名者 p 与陈述 st 在一对连接上创建证明者与验证者会话
+func newRingSessions(p *ringProver, params *ringParams, st *ringStatement, proverConn, verifierConn io.ReadWriter) (*sigmaproof.ProverSession, *sigmaproof.VerifierSession) {
+	return sigmaproof.NewProverSession(proverConn, ringInteractiveProver{p}),
+		sigmaproof.NewVerifierSession(verifierConn, ringInteractiveVerifier{params: params, st: st})
+}
+
+// runRingSessions 并发执行两个会话，各自结束后关闭自己一侧的连接
+func runRingSessions(p *sigmaproof.ProverSession, v *sigmaproof.VerifierSession, proverConn, verifierConn net.Conn) (perr, verr error) {
+	errc := make(chan error, 1)
+	go func() {
+		defer proverConn.Close()
+		errc <- p.Run()
+	}()
+	verr = v.Run()
+	verifierConn.Close()
+	return <-errc, verr
+}
+
+func TestRingSessionAccepts(t *testing.T) {
+	params, st, w, _ := newTestRing(t, 2, 3)
+	for name, conns := range conntest.Pairs(t) {
+		prover, err := newRingProver(rand.Reader, params, st, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, v := newRingSessions(prover, params, st, conns[0], conns[1])
+		if perr, verr := runRingSessions(p, v, conns[0], conns[1]); perr != nil || verr != nil {
+			t.Fatalf("%s: prover %v, verifier %v", name, perr, verr)
+		}
+		if err := p.Step(); !errors.Is(err, sigmaproof.ErrSessionState) {
+			t.Fatalf("%s: prover step after done: got %v, want ErrSessionState", name, err)
+		}
+		if err := v.Step(); !errors.Is(err, sigmaproof.ErrSessionState) {
+			t.Fatalf("%s: verifier step after done: got %v, want ErrSessionState", name, err)
+		}
+	}
+}
+
+// 签名者在 newRingProver 检查之后换用错误的私钥，验证者判定协议 1 或 2 不成立并回告 ErrRejected
+func TestRingSessionWrongWitness(t *testing.T) {
+	params, st, w, users := newTestRing(t, 2, 1)
+	for name, conns := range conntest.Pairs(t) {
+		prover, err := newRingProver(rand.Reader, params, st, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prover.w = &ringWitness{l: w.l, sk: &users[0].sk, u: w.u}
+		p, v := newRingSessions(prover, params, st, conns[0], conns[1])
+		perr, verr := runRingSessions(p, v, conns[0], conns[1])
+		if !errors.Is(perr, sigmaproof.ErrRejected) || !(errors.Is(verr, errProtocol1) || errors.Is(verr, errProtocol2)) {
+			t.Fatalf("%s: prover %v, verifier %v", name, perr, verr)
+		}
+	}
+}
+
+// rw 以独立的读、写两端组成 io.ReadWriter
+type rw struct {
+	io.Reader
+	io.Writer
+}
+
+func TestRingSessionMalformedMessages(t *testing.T) {
+	params, st, w, _ := newTestRing(t, 2, 0)
+	prover, err := newRingProver(rand.Reader, params, st, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 验证者等待承诺时收到挑战
+	var in bytes.Buffer
+	if err := sigmaproof.WriteMessage(&in, sigmaproof.MsgChallenge, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	_, v := newRingSessions(prover, params, st, nil, rw{&in, io.Discard})
+	if err := v.Step(); !errors.Is(err, sigmaproof.ErrUnexpectedMessage) {
+		t.Fatalf("got %v, want ErrUnexpectedMessage", err)
+	}
+
+	// 头部声明的长度超过上限
+	header := binary.BigEndian.AppendUint32([]byte{byte(sigmaproof.MsgCommitment)}, 1<<20+1)
+	_, v = newRingSessions(prover, params, st, nil, rw{bytes.NewReader(header), io.Discard})
+	if err := v.Step(); !errors.Is(err, sigmaproof.ErrMessageTooLarge) {
+		t.Fatalf("got %v, want ErrMessageTooLarge", err)
+	}
+
+	// 长度不符的第一轮消息与响应
+	rv := ringInteractiveVerifier{params: params, st: st}
+	com, err := prover.commit().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := prover.respond(big.NewInt(7)).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rv.Check(com[1:], big.NewInt(7), resp); !errors.Is(err, errEncoding) {
+		t.Fatalf("commitment: got %v, want errEncoding", err)
+	}
+	if err := rv.Check(com, big.NewInt(7), resp[1:]); !errors.Is(err, errEncoding) {
+		t.Fatalf("response: got %v, want errEncoding", err)
+	}
+	if err := rv.Check(com, big.NewInt(7), resp); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,175 @@
+package sigmaproof
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// And 将若干关系合并为一个关系（合取）：公开点与秘密标量依次排列，证据按各关系的顺序拼接，共用同一个挑战
+func And(domain string, rels ...*Relation) *Relation {
+	res := New(domain)
+	for _, r := range rels {
+		pointBase, scalarBase := len(res.points), res.scalars
+		res.points = append(res.points, r.points...)
+		res.scalars += r.scalars
+		for _, eq := range r.eqs {
+			terms := make([]Term, len(eq.terms))
+			for i, term := range eq.terms {
+				terms[i] = Term{X: term.X + Scalar(scalarBase), P: term.P + Point(pointBase)}
+			}
+			res.eqs = append(res.eqs, equation{lhs: eq.lhs + Point(pointBase), terms: terms})
+		}
+	}
+	return res
+}
+
+// Or 1-of-k 析取（Cramer-Damgård-Schoenmakers）：证明者知道其中某一个关系的证据，而不泄露是哪一个
+type Or struct {
+	domain string
+	rels   []*Relation
+}
+
+// NewOr 由若干关系构造析取
+func NewOr(domain string, rels ...*Relation) *Or {
+	return &Or{domain: domain, rels: rels}
+}
+
+// OrProof 析取证明：各分支的挑战 c_j（Σ c_j 等于总挑战）与响应
+type OrProof struct {
+	C []big.Int
+	Z [][]big.Int
+}
+
+// Prove 用第 index 个关系的证据生成析取证明；其余分支先选挑战再由模拟器生成
+func (o *Or) Prove(rand io.Reader, index int, witness []*big.Int, extra ...[]byte) (*OrProof, error) {
+	if index < 0 || index >= len(o.rels) || !o.rels[index].Holds(witness) {
+		return nil, ErrInvalidWitness
+	}
+	proof := &OrProof{C: make([]big.Int, len(o.rels)), Z: make([][]big.Int, len(o.rels))}
+	commitments := make([][]twistededwards.PointAffine, len(o.rels))
+	sum := new(big.Int)
+	for j, r := range o.rels {
+		if j == index {
+			continue
+		}
+		c, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		tr, err := r.Simulate(rand, c)
+		if err != nil {
+			return nil, err
+		}
+		proof.C[j].Set(c)
+		proof.Z[j], commitments[j] = tr.Z, tr.Commitments
+		sum.Add(sum, c)
+	}
+
+	prover, err := o.rels[index].NewProver(rand, witness)
+	if err != nil {
+		return nil, err
+	}
+	commitments[index] = prover.Commit()
+
+	// c_index = c − Σ_{j≠index} c_j
+	c := o.challenge(commitments, extra)
+	ci := curveutil.ModOrder(sum.Sub(c, sum))
+	proof.C[index].Set(ci)
+	proof.Z[index] = prover.Respond(ci)
+	return proof, nil
+}
+
+// Verify 验证析取证明，extra 须与生成时一致
+func (o *Or) Verify(proof *OrProof, extra ...[]byte) error {
+	if len(proof.C) != len(o.rels) || len(proof.Z) != len(o.rels) {
+		return ErrInvalidProof
+	}
+	commitments := make([][]twistededwards.PointAffine, len(o.rels))
+	sum := new(big.Int)
+	for j, r := range o.rels {
+		if len(proof.Z[j]) != r.scalars {
+			return ErrInvalidProof
+		}
+		commitments[j] = r.recompute(&proof.C[j], proof.Z[j])
+		sum.Add(sum, &proof.C[j])
+	}
+	c := o.challenge(commitments, extra)
+	if c.Cmp(curveutil.ModOrder(sum)) != 0 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// challenge 计算 H(k, 各关系的域与陈述, extra..., 各分支承诺) mod order
+func (o *Or) challenge(commitments [][]twistededwards.PointAffine, extra [][]byte) *big.Int {
+	header := binary.BigEndian.AppendUint32(nil, uint32(len(o.rels)))
+	var data [][]byte
+	for _, r := range o.rels {
+		st := r.statement()
+		header = binary.BigEndian.AppendUint32(header, uint32(len(st)))
+		data = append(append(data, []byte(r.domain)), st...)
+	}
+	data = append([][]byte{header}, data...)
+	data = append(data, extra...)
+	for _, T := range commitments {
+		for i := range T {
+			data = append(data, T[i].Marshal())
+		}
+	}
+	return curveutil.HashToScalar(o.domain, data...)
+}
+
+// MarshalBinary 序列化为 k（4 字节）|| 各分支（响应个数（4 字节）|| c_j || z_j…）
+func (proof *OrProof) MarshalBinary() ([]byte, error) {
+	res := binary.BigEndian.AppendUint32(nil, uint32(len(proof.C)))
+	for j := range proof.C {
+		branch := Proof{Z: proof.Z[j]}
+		branch.C.Set(&proof.C[j])
+		b, err := branch.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res = binary.BigEndian.AppendUint32(res, uint32(len(proof.Z[j])))
+		res = append(res, b...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明
+func (proof *OrProof) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return ErrInvalidProof
+	}
+	k := int(binary.BigEndian.Uint32(data))
+	off := 4
+	var cs []big.Int
+	var zs [][]big.Int
+	for j := 0; j < k; j++ {
+		if len(data)-off < 4 {
+			return ErrInvalidProof
+		}
+		n := int(binary.BigEndian.Uint32(data[off:]))
+		off += 4
+		size := (1 + n) * curveutil.ScalarSize
+		if len(data)-off < size {
+			return ErrInvalidProof
+		}
+		var branch Proof
+		if err := branch.UnmarshalBinary(data[off : off+size]); err != nil {
+			return err
+		}
+		off += size
+		cs = append(cs, branch.C)
+		zs = append(zs, branch.Z)
+	}
+	if off != len(data) {
+		return ErrInvalidProof
+	}
+	proof.C, proof.Z = cs, zs
+	return nil
+}
@@ -0,0 +1,54 @@
+package sigmaproof
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+var ErrExtraction = errors.New("sigmaproof: transcripts do not yield a witness")
+
+// Extract 特殊可靠性提取器：由承诺相同、挑战不同的两个被接受副本求出证据 x = (z − z') / (c − c')
+func (r *Relation) Extract(t1, t2 *Transcript) ([]*big.Int, error) {
+	if !r.Accepts(t1) || !r.Accepts(t2) {
+		return nil, ErrExtraction
+	}
+	for e := range t1.Commitments {
+		if !t1.Commitments[e].Equal(&t2.Commitments[e]) {
+			return nil, ErrExtraction
+		}
+	}
+	curve := twistededwards.GetEdwardsCurve()
+	inv := new(big.Int).ModInverse(curveutil.ModOrder(new(big.Int).Sub(&t1.C, &t2.C)), &curve.Order)
+	if inv == nil {
+		return nil, ErrExtraction
+	}
+	witness := make([]*big.Int, r.scalars)
+	for j := range witness {
+		x := new(big.Int).Sub(&t1.Z[j], &t2.Z[j])
+		witness[j] = curveutil.ModOrder(x.Mul(x, inv))
+	}
+	if !r.Holds(witness) {
+		return nil, ErrExtraction
+	}
+	return witness, nil
+}
+
+// Rewind 把 p 当作黑盒回卷：一次 Commit 之后对两个从 rand 选取的挑战各 Respond 一次，再用 Extract 求出证据
+func Rewind(rand io.Reader, p *Prover) ([]*big.Int, error) {
+	commitments := p.Commit()
+	transcripts := make([]*Transcript, 2)
+	for i := range transcripts {
+		c, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		transcripts[i] = &Transcript{Commitments: commitments, Z: p.Respond(c)}
+		transcripts[i].C.Set(c)
+	}
+	return p.r.Extract(transcripts[0], transcripts[1])
+}
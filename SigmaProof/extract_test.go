@@ -0,0 +1,87 @@
+package sigmaproof
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+func mustScalar(t *testing.T) *big.Int {
+	t.Helper()
+	k, err := curveutil.RandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// commitmentRelation 返回 Y = r·G + m·h 的关系及其证据 (r, m)；
+// 各证明模块的真实关系在各自包的测试中提取
+func commitmentRelation(t *testing.T) (*Relation, []*big.Int) {
+	t.Helper()
+	G := twistededwards.GetEdwardsCurve().Base
+	h := curveutil.HashToPoint("LYcode/SigmaProof/test", []byte("h"))
+	r, m := mustScalar(t), big.NewInt(123456789)
+	rel := New("test/commitment")
+	sr, sm := rel.Scalar(), rel.Scalar()
+	Y := curveutil.MultiScalarMul([]twistededwards.PointAffine{G, h}, []*big.Int{r, m})
+	rel.Equation(rel.Point(Y), Term{X: sr, P: rel.Point(G)}, Term{X: sm, P: rel.Point(h)})
+	return rel, []*big.Int{r, m}
+}
+
+func TestRewindExtractsWitness(t *testing.T) {
+	rel, witness := commitmentRelation(t)
+	p, err := rel.NewProver(rand.Reader, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Rewind(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range witness {
+		if got[j].Cmp(witness[j]) != 0 {
+			t.Fatalf("scalar %d: got %v, want %v", j, got[j], witness[j])
+		}
+	}
+}
+
+func TestExtractRejects(t *testing.T) {
+	rel, witness := commitmentRelation(t)
+	p, err := rel.NewProver(rand.Reader, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments := p.Commit()
+	c := mustScalar(t)
+	t1 := &Transcript{Commitments: commitments, Z: p.Respond(c)}
+	t1.C.Set(c)
+
+	// 同一挑战的两个副本
+	t2 := &Transcript{Commitments: commitments, Z: p.Respond(c)}
+	t2.C.Set(c)
+	if _, err := rel.Extract(t1, t2); !errors.Is(err, ErrExtraction) {
+		t.Fatalf("duplicate challenge: got %v, want ErrExtraction", err)
+	}
+
+	// 承诺不同的两个副本
+	other, err := rel.Transcript(rand.Reader, witness, mustScalar(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rel.Extract(t1, other); !errors.Is(err, ErrExtraction) {
+		t.Fatalf("different commitments: got %v, want ErrExtraction", err)
+	}
+
+	// 不被接受的副本
+	bad := &Transcript{Commitments: commitments, Z: p.Respond(new(big.Int).Add(c, big.NewInt(1)))}
+	bad.C.Set(c)
+	if _, err := rel.Extract(t1, bad); !errors.Is(err, ErrExtraction) {
+		t.Fatalf("rejected transcript: got %v, want ErrExtraction", err)
+	}
+}
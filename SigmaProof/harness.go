@@ -0,0 +1,109 @@
+package sigmaproof
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+)
+
+const (
+	// harnessBuckets 每个分量按编码末字节的低 4 位分桶
+	harnessBuckets = 16
+	// criticalZ 单个分量检验的标准正态分位数（约 p = 1e-5），分量较多时仍很少误报
+	criticalZ = 4.265
+)
+
+var ErrSampleShape = errors.New("sigmaproof: samplers produce transcripts of different shapes")
+
+// Sampler 生成一个被接受的副本，返回其各分量的编码（如 Transcript.Components）
+type Sampler func() ([][]byte, error)
+
+// Comparison 真实副本与模拟副本逐分量的双样本卡方检验结果
+type Comparison struct {
+	Samples   int
+	ChiSquare []float64 // 各分量的统计量
+	Critical  float64   // 自由度为 harnessBuckets − 1 时的临界值
+}
+
+// Indistinguishable 报告是否所有分量的统计量都未超过临界值
+func (c *Comparison) Indistinguishable() bool {
+	for _, x := range c.ChiSquare {
+		if x > c.Critical {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareSamplers 各取 n 个真实与模拟副本，比较每个分量的分布。
+// 这是诚实验证者零知识的统计证据，不是证明；n 建议不少于 200。
+func CompareSamplers(honest, sim Sampler, n int) (*Comparison, error) {
+	var realCounts, simCounts [][harnessBuckets]int
+	for i := 0; i < n; i++ {
+		for _, s := range []struct {
+			sample Sampler
+			counts *[][harnessBuckets]int
+		}{{honest, &realCounts}, {sim, &simCounts}} {
+			comps, err := s.sample()
+			if err != nil {
+				return nil, err
+			}
+			if *s.counts == nil {
+				*s.counts = make([][harnessBuckets]int, len(comps))
+			}
+			if len(comps) != len(*s.counts) {
+				return nil, ErrSampleShape
+			}
+			for j, b := range comps {
+				if len(b) == 0 {
+					return nil, ErrSampleShape
+				}
+				(*s.counts)[j][b[len(b)-1]%harnessBuckets]++
+			}
+		}
+	}
+	if len(realCounts) != len(simCounts) {
+		return nil, ErrSampleShape
+	}
+
+	// χ² = Σ (R_b − S_b)² / (R_b + S_b)，两组样本数相等
+	res := &Comparison{Samples: n, ChiSquare: make([]float64, len(realCounts)), Critical: chiSquareCritical(harnessBuckets - 1)}
+	for j := range realCounts {
+		for b := 0; b < harnessBuckets; b++ {
+			r, s := float64(realCounts[j][b]), float64(simCounts[j][b])
+			if r+s > 0 {
+				res.ChiSquare[j] += (r - s) * (r - s) / (r + s)
+			}
+		}
+	}
+	return res, nil
+}
+
+// chiSquareCritical 用 Wilson-Hilferty 近似计算自由度 k 的卡方分布上 criticalZ 分位点
+func chiSquareCritical(k int) float64 {
+	v := 2 / (9 * float64(k))
+	return float64(k) * math.Pow(1-v+criticalZ*math.Sqrt(v), 3)
+}
+
+// CompareSimulator 在随机挑战下比较该关系的真实副本（用 witness）与 Simulate 的输出，随机数均从 rand 读取
+func (r *Relation) CompareSimulator(rand io.Reader, witness []*big.Int, n int) (*Comparison, error) {
+	sampler := func(transcript func(c *big.Int) (*Transcript, error)) Sampler {
+		return func() ([][]byte, error) {
+			c, err := curveutil.RandomScalar(rand)
+			if err != nil {
+				return nil, err
+			}
+			tr, err := transcript(c)
+			if err != nil {
+				return nil, err
+			}
+			return tr.Components(), nil
+		}
+	}
+	honest := sampler(func(c *big.Int) (*Transcript, error) { return r.Transcript(rand, witness, c) })
+	sim := sampler(func(c *big.Int) (*Transcript, error) { return r.Simulate(rand, c) })
+	return CompareSamplers(honest, sim, n)
+}
@@ -0,0 +1,86 @@
+package sigmaproof
+
+import (
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+)
+
+// Proof 非交互证明：挑战 c 与各秘密标量的响应 z_x = k_x + c·x
+type Proof struct {
+	C big.Int
+	Z []big.Int
+}
+
+// Prove 用随机源 rand 与证据 witness（按 Scalar 声明顺序）生成证明，extra 为额外绑定进挑战的数据
+func (r *Relation) Prove(rand io.Reader, witness []*big.Int, extra ...[]byte) (*Proof, error) {
+	p, err := r.NewProver(rand, witness)
+	if err != nil {
+		return nil, err
+	}
+	c := r.challenge(p.Commit(), extra)
+	proof := &Proof{Z: p.Respond(c)}
+	proof.C.Set(c)
+	return proof, nil
+}
+
+// Verify 验证证明，extra 须与生成时一致
+func (r *Relation) Verify(proof *Proof, extra ...[]byte) error {
+	if len(proof.Z) != r.scalars {
+		return ErrInvalidProof
+	}
+	c := r.challenge(r.recompute(&proof.C, proof.Z), extra)
+	if c.Cmp(&proof.C) != 0 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+func randomScalars(rand io.Reader, n int) ([]*big.Int, error) {
+	res := make([]*big.Int, n)
+	for j := range res {
+		k, err := curveutil.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		res[j] = k
+	}
+	return res, nil
+}
+
+// Size 返回该关系的证明序列化后的长度
+func (r *Relation) Size() int {
+	return (1 + r.scalars) * curveutil.ScalarSize
+}
+
+// MarshalBinary 序列化为 c || z_0 || z_1 || …
+func (proof *Proof) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 0, (1+len(proof.Z))*curveutil.ScalarSize)
+	c := curveutil.ScalarBytes(&proof.C)
+	res = append(res, c[:]...)
+	for j := range proof.Z {
+		z := curveutil.ScalarBytes(&proof.Z[j])
+		res = append(res, z[:]...)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的输出恢复证明，响应个数由长度确定
+func (proof *Proof) UnmarshalBinary(data []byte) error {
+	if len(data) < curveutil.ScalarSize || len(data)%curveutil.ScalarSize != 0 {
+		return ErrInvalidProof
+	}
+	n := len(data)/curveutil.ScalarSize - 1
+	ks := make([]big.Int, n+1)
+	for i := range ks {
+		k, err := curveutil.ScalarFromBytes(data[i*curveutil.ScalarSize : (i+1)*curveutil.ScalarSize])
+		if err != nil {
+			return err
+		}
+		ks[i].Set(k)
+	}
+	proof.C.Set(&ks[0])
+	proof.Z = ks[1:]
+	return nil
+}
@@ -0,0 +1,276 @@
+package sigmaproof
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// maxMessageSize 单条消息负载的上限，防止对端声明过大的长度
+const maxMessageSize = 1 << 20
+
+var (
+	ErrUnexpectedMessage = errors.New("sigmaproof: unexpected message type")
+	ErrMessageTooLarge   = errors.New("sigmaproof: message too large")
+	ErrSessionState      = errors.New("sigmaproof: session already finished")
+	ErrRejected          = errors.New("sigmaproof: verifier rejected the proof")
+)
+
+// MessageType 交互消息的类型
+type MessageType byte
+
+const (
+	MsgCommitment MessageType = iota + 1 // 证明者 → 验证者：第一轮承诺
+	MsgChallenge                         // 验证者 → 证明者：挑战 c
+	MsgResponse                          // 证明者 → 验证者：响应
+	MsgResult                            // 验证者 → 证明者：验证结果（1 字节，1 为接受）
+)
+
+// WriteMessage 写出一条消息：类型（1 字节）|| 负载长度（4 字节）|| 负载
+func WriteMessage(w io.Writer, t MessageType, payload []byte) error {
+	if len(payload) > maxMessageSize {
+		return ErrMessageTooLarge
+	}
+	header := binary.BigEndian.AppendUint32([]byte{byte(t)}, uint32(len(payload)))
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+// ReadMessage 读取一条消息，类型不是 want 时返回 ErrUnexpectedMessage
+func ReadMessage(r io.Reader, want MessageType) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if MessageType(header[0]) != want {
+		return nil, ErrUnexpectedMessage
+	}
+	n := binary.BigEndian.Uint32(header[1:])
+	if n > maxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// InteractiveProver 三轮公开掷币协议的证明者，承诺与响应以编码后的形式交换
+type InteractiveProver interface {
+	Commit() ([]byte, error)
+	Respond(c *big.Int) ([]byte, error)
+}
+
+// InteractiveVerifier 对一次副本（承诺、挑战、响应）做出判定
+type InteractiveVerifier interface {
+	Check(commitment []byte, c *big.Int, response []byte) error
+}
+
+type sessionState int
+
+const (
+	stateCommit sessionState = iota
+	stateRespond
+	stateResult
+	stateDone
+)
+
+// ProverSession 证明者一侧的状态机：发送承诺 → 收到挑战后发送响应 → 读取验证结果
+type ProverSession struct {
+	conn  io.ReadWriter
+	p     InteractiveProver
+	state sessionState
+}
+
+// VerifierSession 验证者一侧的状态机：读取承诺后发送随机挑战（取自 crypto/rand） → 读取响应并判定 → 回告结果
+type VerifierSession struct {
+	conn       io.ReadWriter
+	v          InteractiveVerifier
+	state      sessionState
+	commitment []byte
+	c          *big.Int
+	err        error
+}
+
+// NewProverSession 在 conn 上为 p 创建证明者会话
+func NewProverSession(conn io.ReadWriter, p InteractiveProver) *ProverSession {
+	return &ProverSession{conn: conn, p: p}
+}
+
+// NewVerifierSession 在 conn 上为 v 创建验证者会话
+func NewVerifierSession(conn io.ReadWriter, v InteractiveVerifier) *VerifierSession {
+	return &VerifierSession{conn: conn, v: v}
+}
+
+// Step 执行一步，会话结束后返回 ErrSessionState
+func (s *ProverSession) Step() error {
+	switch s.state {
+	case stateCommit:
+		com, err := s.p.Commit()
+		if err != nil {
+			return err
+		}
+		if err := WriteMessage(s.conn, MsgCommitment, com); err != nil {
+			return err
+		}
+		s.state = stateRespond
+	case stateRespond:
+		payload, err := ReadMessage(s.conn, MsgChallenge)
+		if err != nil {
+			return err
+		}
+		c, err := curveutil.ScalarFromBytes(payload)
+		if err != nil {
+			return err
+		}
+		resp, err := s.p.Respond(c)
+		if err != nil {
+			return err
+		}
+		if err := WriteMessage(s.conn, MsgResponse, resp); err != nil {
+			return err
+		}
+		s.state = stateResult
+	case stateResult:
+		payload, err := ReadMessage(s.conn, MsgResult)
+		if err != nil {
+			return err
+		}
+		s.state = stateDone
+		if len(payload) != 1 || payload[0] != 1 {
+			return ErrRejected
+		}
+	default:
+		return ErrSessionState
+	}
+	return nil
+}
+
+// Run 执行到会话结束，验证者拒绝时返回 ErrRejected
+func (s *ProverSession) Run() error {
+	for s.state != stateDone {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step 执行一步，会话结束后返回 ErrSessionState
+func (s *VerifierSession) Step() error {
+	switch s.state {
+	case stateCommit:
+		com, err := ReadMessage(s.conn, MsgCommitment)
+		if err != nil {
+			return err
+		}
+		c, err := curveutil.RandomScalar(crand.Reader)
+		if err != nil {
+			return err
+		}
+		b := curveutil.ScalarBytes(c)
+		if err := WriteMessage(s.conn, MsgChallenge, b[:]); err != nil {
+			return err
+		}
+		s.commitment, s.c = com, c
+		s.state = stateRespond
+	case stateRespond:
+		resp, err := ReadMessage(s.conn, MsgResponse)
+		if err != nil {
+			return err
+		}
+		s.err = s.v.Check(s.commitment, s.c, resp)
+		s.state = stateResult
+	case stateResult:
+		result := []byte{1}
+		if s.err != nil {
+			result[0] = 0
+		}
+		if err := WriteMessage(s.conn, MsgResult, result); err != nil {
+			return err
+		}
+		s.state = stateDone
+	default:
+		return ErrSessionState
+	}
+	return nil
+}
+
+// Run 执行到会话结束，返回验证结果：接受时为 nil，否则为验证者给出的错误
+func (s *VerifierSession) Run() error {
+	for s.state != stateDone {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+// relationProver 以 InteractiveProver 的形式包装 Prover，承诺编码为各点的压缩形式依次拼接
+type relationProver struct{ p *Prover }
+
+func (rp relationProver) Commit() ([]byte, error) {
+	var res []byte
+	for _, T := range rp.p.Commit() {
+		b := T.Bytes()
+		res = append(res, b[:]...)
+	}
+	return res, nil
+}
+
+func (rp relationProver) Respond(c *big.Int) ([]byte, error) {
+	proof := &Proof{Z: rp.p.Respond(c)}
+	b, err := proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// 去掉 MarshalBinary 中占位的 c
+	return b[curveutil.ScalarSize:], nil
+}
+
+type relationVerifier struct{ r *Relation }
+
+func (rv relationVerifier) Check(commitment []byte, c *big.Int, response []byte) error {
+	if len(commitment) != len(rv.r.eqs)*curveutil.PointSize || len(response) != rv.r.scalars*curveutil.ScalarSize {
+		return ErrInvalidProof
+	}
+	tr := &Transcript{Commitments: make([]twistededwards.PointAffine, len(rv.r.eqs))}
+	for e := range tr.Commitments {
+		T, err := curveutil.PointFromBytes(commitment[e*curveutil.PointSize : (e+1)*curveutil.PointSize])
+		if err != nil {
+			return ErrInvalidProof
+		}
+		tr.Commitments[e] = T
+	}
+	var proof Proof
+	if err := proof.UnmarshalBinary(append(make([]byte, curveutil.ScalarSize), response...)); err != nil {
+		return ErrInvalidProof
+	}
+	tr.C.Set(c)
+	tr.Z = proof.Z
+	if !rv.r.Accepts(tr) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// NewProverSession 用随机源 rand 与证据在 conn 上创建该关系的交互式证明者会话（与 Prove 的 Fiat-Shamir 模式相互独立）
+func (r *Relation) NewProverSession(rand io.Reader, conn io.ReadWriter, witness []*big.Int) (*ProverSession, error) {
+	p, err := r.NewProver(rand, witness)
+	if err != nil {
+		return nil, err
+	}
+	return NewProverSession(conn, relationProver{p}), nil
+}
+
+// NewVerifierSession 在 conn 上创建该关系的交互式验证者会话
+func (r *Relation) NewVerifierSession(conn io.ReadWriter) *VerifierSession {
+	return NewVerifierSession(conn, relationVerifier{r})
+}
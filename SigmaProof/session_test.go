@@ -0,0 +1,115 @@
+package sigmaproof
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+
+	"MissionYang/internal/conntest"
+)
+
+// runSessions 在一对连接上并发执行证明者与验证者会话，各自结束后关闭自己一侧的连接
+func runSessions(p *ProverSession, v *VerifierSession, proverConn, verifierConn net.Conn) (perr, verr error) {
+	errc := make(chan error, 1)
+	go func() {
+		defer proverConn.Close()
+		errc <- p.Run()
+	}()
+	verr = v.Run()
+	verifierConn.Close()
+	return <-errc, verr
+}
+
+func TestSessionAccepts(t *testing.T) {
+	rel, witness := commitmentRelation(t)
+	for name, conns := range conntest.Pairs(t) {
+		p, err := rel.NewProverSession(rand.Reader, conns[0], witness)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := rel.NewVerifierSession(conns[1])
+		if perr, verr := runSessions(p, v, conns[0], conns[1]); perr != nil || verr != nil {
+			t.Fatalf("%s: prover %v, verifier %v", name, perr, verr)
+		}
+		if err := p.Step(); !errors.Is(err, ErrSessionState) {
+			t.Fatalf("%s: prover step after done: got %v, want ErrSessionState", name, err)
+		}
+		if err := v.Step(); !errors.Is(err, ErrSessionState) {
+			t.Fatalf("%s: verifier step after done: got %v, want ErrSessionState", name, err)
+		}
+	}
+}
+
+// 证据不满足关系时（绕过 NewProver 的检查），验证者判定 ErrInvalidProof 并回告证明者 ErrRejected
+func TestSessionWrongWitness(t *testing.T) {
+	rel, witness := commitmentRelation(t)
+	wrong := []*big.Int{witness[0], new(big.Int).Add(witness[1], big.NewInt(1))}
+	for name, conns := range conntest.Pairs(t) {
+		nonces, err := randomScalars(rand.Reader, rel.scalars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := NewProverSession(conns[0], relationProver{&Prover{r: rel, witness: wrong, nonces: nonces}})
+		v := rel.NewVerifierSession(conns[1])
+		perr, verr := runSessions(p, v, conns[0], conns[1])
+		if !errors.Is(perr, ErrRejected) || !errors.Is(verr, ErrInvalidProof) {
+			t.Fatalf("%s: prover %v, verifier %v", name, perr, verr)
+		}
+	}
+}
+
+// rw 以独立的读、写两端组成 io.ReadWriter
+type rw struct {
+	io.Reader
+	io.Writer
+}
+
+func TestSessionUnexpectedMessage(t *testing.T) {
+	rel, witness := commitmentRelation(t)
+
+	// 验证者等待承诺时收到响应
+	var in bytes.Buffer
+	if err := WriteMessage(&in, MsgResponse, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	v := rel.NewVerifierSession(rw{&in, io.Discard})
+	if err := v.Step(); !errors.Is(err, ErrUnexpectedMessage) {
+		t.Fatalf("verifier: got %v, want ErrUnexpectedMessage", err)
+	}
+
+	// 证明者发出承诺后等待挑战时收到结果
+	in.Reset()
+	if err := WriteMessage(&in, MsgResult, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := rel.NewProverSession(rand.Reader, rw{&in, io.Discard}, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Step(); !errors.Is(err, ErrUnexpectedMessage) {
+		t.Fatalf("prover: got %v, want ErrUnexpectedMessage", err)
+	}
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	header := binary.BigEndian.AppendUint32([]byte{byte(MsgCommitment)}, maxMessageSize+1)
+	if _, err := ReadMessage(bytes.NewReader(header), MsgCommitment); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("read: got %v, want ErrMessageTooLarge", err)
+	}
+	rel, _ := commitmentRelation(t)
+	v := rel.NewVerifierSession(rw{bytes.NewReader(header), io.Discard})
+	if err := v.Step(); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("verifier: got %v, want ErrMessageTooLarge", err)
+	}
+	if err := WriteMessage(io.Discard, MsgCommitment, make([]byte, maxMessageSize+1)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("write: got %v, want ErrMessageTooLarge", err)
+	}
+}
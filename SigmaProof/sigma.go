@@ -0,0 +1,162 @@
+// Package sigmaproof 为线性关系提供通用的 Sigma 协议：陈述声明为群元素上的线性方程组
+//
+//	P_e = Σ_j x_{e,j}·Q_{e,j}
+//
+// 其中 P、Q 为公开点，x 为秘密标量。框架统一生成承诺、Fiat-Shamir 挑战、响应、验证与序列化。
+package sigmaproof
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+var (
+	ErrInvalidWitness = errors.New("sigmaproof: witness does not satisfy relation")
+	ErrInvalidProof   = errors.New("sigmaproof: invalid proof")
+)
+
+// Point 关系中公开点的句柄
+type Point int
+
+// Scalar 关系中秘密标量的句柄
+type Scalar int
+
+// Term 方程右侧的一项 x·Q
+type Term struct {
+	X Scalar
+	P Point
+}
+
+type equation struct {
+	lhs   Point
+	terms []Term
+}
+
+// Relation 线性关系陈述；domain 区分不同用途的证明
+type Relation struct {
+	domain  string
+	points  []twistededwards.PointAffine
+	scalars int
+	eqs     []equation
+}
+
+// New 创建空关系
+func New(domain string) *Relation {
+	return &Relation{domain: domain}
+}
+
+// Point 加入公开点并返回其句柄
+func (r *Relation) Point(p twistededwards.PointAffine) Point {
+	r.points = append(r.points, p)
+	return Point(len(r.points) - 1)
+}
+
+// Scalar 声明一个秘密标量并返回其句柄，证据按声明顺序给出
+func (r *Relation) Scalar() Scalar {
+	r.scalars++
+	return Scalar(r.scalars - 1)
+}
+
+// Equation 加入方程 lhs = Σ terms
+func (r *Relation) Equation(lhs Point, terms ...Term) {
+	r.eqs = append(r.eqs, equation{lhs: lhs, terms: terms})
+}
+
+// Scalars 返回秘密标量的个数
+func (r *Relation) Scalars() int {
+	return r.scalars
+}
+
+// Holds 检查证据是否满足全部方程
+func (r *Relation) Holds(witness []*big.Int) bool {
+	if len(witness) != r.scalars {
+		return false
+	}
+	for _, eq := range r.eqs {
+		rhs := r.combine(eq.terms, witness)
+		if !rhs.Equal(&r.points[eq.lhs]) {
+			return false
+		}
+	}
+	return true
+}
+
+// combine 计算 Σ ks[x]·Q
+func (r *Relation) combine(terms []Term, ks []*big.Int) twistededwards.PointAffine {
+	points := make([]twistededwards.PointAffine, len(terms))
+	scalars := make([]*big.Int, len(terms))
+	for i, term := range terms {
+		points[i] = r.points[term.P]
+		scalars[i] = ks[term.X]
+	}
+	return curveutil.MultiScalarMul(points, scalars)
+}
+
+// commit 计算各方程的承诺 T_e = Σ k_x·Q
+func (r *Relation) commit(nonces []*big.Int) []twistededwards.PointAffine {
+	res := make([]twistededwards.PointAffine, len(r.eqs))
+	for e, eq := range r.eqs {
+		res[e] = r.combine(eq.terms, nonces)
+	}
+	return res
+}
+
+// respond 计算 z_x = k_x + c·w_x mod order
+func (r *Relation) respond(nonces, witness []*big.Int, c *big.Int) []big.Int {
+	res := make([]big.Int, r.scalars)
+	for j := range res {
+		z := new(big.Int).Mul(c, witness[j])
+		res[j].Set(curveutil.ModOrder(z.Add(z, nonces[j])))
+	}
+	return res
+}
+
+// recompute 由挑战与响应重算承诺 T_e = Σ z_x·Q − c·P_e
+func (r *Relation) recompute(c *big.Int, z []big.Int) []twistededwards.PointAffine {
+	zs := make([]*big.Int, len(z))
+	for j := range z {
+		zs[j] = &z[j]
+	}
+	negC := new(big.Int).Neg(c)
+	res := make([]twistededwards.PointAffine, len(r.eqs))
+	for e, eq := range r.eqs {
+		var ind twistededwards.PointAffine
+		res[e] = r.combine(eq.terms, zs)
+		ind.ScalarMultiplication(&r.points[eq.lhs], negC)
+		res[e].Add(&res[e], &ind)
+	}
+	return res
+}
+
+// statement 返回关系的结构编码（各方程的句柄）与全部公开点，供挑战哈希使用
+func (r *Relation) statement() [][]byte {
+	shape := binary.BigEndian.AppendUint32(nil, uint32(r.scalars))
+	shape = binary.BigEndian.AppendUint32(shape, uint32(len(r.eqs)))
+	for _, eq := range r.eqs {
+		shape = binary.BigEndian.AppendUint32(shape, uint32(eq.lhs))
+		shape = binary.BigEndian.AppendUint32(shape, uint32(len(eq.terms)))
+		for _, term := range eq.terms {
+			shape = binary.BigEndian.AppendUint32(shape, uint32(term.X))
+			shape = binary.BigEndian.AppendUint32(shape, uint32(term.P))
+		}
+	}
+	data := [][]byte{shape}
+	for i := range r.points {
+		data = append(data, r.points[i].Marshal())
+	}
+	return data
+}
+
+// challenge 计算 H(关系结构, 公开点, extra..., 承诺) mod order
+func (r *Relation) challenge(commitments []twistededwards.PointAffine, extra [][]byte) *big.Int {
+	data := append(r.statement(), extra...)
+	for i := range commitments {
+		data = append(data, commitments[i].Marshal())
+	}
+	return curveutil.HashToScalar(r.domain, data...)
+}
@@ -0,0 +1,103 @@
+package sigmaproof
+
+import (
+	"io"
+	"math/big"
+
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// Transcript 交互式协议的一次副本：承诺 T、挑战 c 与响应 z
+type Transcript struct {
+	Commitments []twistededwards.PointAffine
+	C           big.Int
+	Z           []big.Int
+}
+
+// Prover 交互式证明者：先 Commit 再对验证者的挑战 Respond，每个 Prover 只能使用一次
+// （对同一承诺回应两个不同挑战会泄露证据，见 Rewind）
+type Prover struct {
+	r       *Relation
+	witness []*big.Int
+	nonces  []*big.Int
+}
+
+// NewProver 用证据创建交互式证明者，承诺随机数从 rand 读取
+func (r *Relation) NewProver(rand io.Reader, witness []*big.Int) (*Prover, error) {
+	if !r.Holds(witness) {
+		return nil, ErrInvalidWitness
+	}
+	nonces, err := randomScalars(rand, r.scalars)
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{r: r, witness: witness, nonces: nonces}, nil
+}
+
+// Commit 返回第一轮消息 T_e = Σ k_x·Q
+func (p *Prover) Commit() []twistededwards.PointAffine {
+	return p.r.commit(p.nonces)
+}
+
+// Respond 返回对挑战 c 的响应 z_x = k_x + c·x
+func (p *Prover) Respond(c *big.Int) []big.Int {
+	return p.r.respond(p.nonces, p.witness, curveutil.ModOrder(c))
+}
+
+// Transcript 用证据对给定挑战 c 生成真实副本（诚实验证者）
+func (r *Relation) Transcript(rand io.Reader, witness []*big.Int, c *big.Int) (*Transcript, error) {
+	p, err := r.NewProver(rand, witness)
+	if err != nil {
+		return nil, err
+	}
+	tr := &Transcript{Commitments: p.Commit(), Z: p.Respond(c)}
+	tr.C.Set(curveutil.ModOrder(c))
+	return tr, nil
+}
+
+// Simulate 不用证据，对给定挑战 c 生成可被接受的副本（诚实验证者零知识模拟器）：
+// 先均匀选取 z，再由验证方程反解 T
+func (r *Relation) Simulate(rand io.Reader, c *big.Int) (*Transcript, error) {
+	zs, err := randomScalars(rand, r.scalars)
+	if err != nil {
+		return nil, err
+	}
+	tr := &Transcript{Z: make([]big.Int, len(zs))}
+	for j := range zs {
+		tr.Z[j].Set(zs[j])
+	}
+	tr.C.Set(curveutil.ModOrder(c))
+	tr.Commitments = r.recompute(&tr.C, tr.Z)
+	return tr, nil
+}
+
+// Accepts 检查副本是否满足验证方程 Σ z_x·Q = T_e + c·P_e
+func (r *Relation) Accepts(tr *Transcript) bool {
+	if len(tr.Z) != r.scalars || len(tr.Commitments) != len(r.eqs) {
+		return false
+	}
+	expected := r.recompute(&tr.C, tr.Z)
+	for e := range expected {
+		if !expected[e].Equal(&tr.Commitments[e]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Components 返回副本各分量的编码，供统计检验使用
+func (tr *Transcript) Components() [][]byte {
+	res := make([][]byte, 0, len(tr.Commitments)+1+len(tr.Z))
+	for i := range tr.Commitments {
+		res = append(res, tr.Commitments[i].Marshal())
+	}
+	c := curveutil.ScalarBytes(&tr.C)
+	res = append(res, c[:])
+	for j := range tr.Z {
+		z := curveutil.ScalarBytes(&tr.Z[j])
+		res = append(res, z[:])
+	}
+	return res
+}
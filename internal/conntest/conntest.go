@@ -0,0 +1,51 @@
+// Package conntest 为交互式会话的测试提供成对的连接。
+package conntest
+
+import (
+	"net"
+	"testing"
+)
+
+// TCPPair 在本地回环地址上建立一对 TCP 连接，测试结束时关闭
+func TCPPair(t testing.TB) (net.Conn, net.Conn) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := <-accepted
+	if server == nil {
+		client.Close()
+		t.Fatal("accept failed")
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+// Pairs 返回 net.Pipe 与回环 TCP 两种连接对，键为传输名称
+func Pairs(t testing.TB) map[string][2]net.Conn {
+	t.Helper()
+	pipeP, pipeV := net.Pipe()
+	t.Cleanup(func() {
+		pipeP.Close()
+		pipeV.Close()
+	})
+	tcpP, tcpV := TCPPair(t)
+	return map[string][2]net.Conn{
+		"pipe": {pipeP, pipeV},
+		"tcp":  {tcpP, tcpV},
+	}
+}
@@ -0,0 +1,138 @@
+// Package curveutil 提供各证明模块共用的曲线工具：随机标量、带域分隔的哈希和编解码。
+package curveutil
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// ScalarSize 标量编码长度（字节）
+const ScalarSize = fr.Bytes
+
+// PointSize 压缩点编码长度（字节）
+const PointSize = fr.Bytes
+
+var (
+	ErrScalarEncoding = errors.New("curveutil: invalid scalar encoding")
+	ErrPointEncoding  = errors.New("curveutil: invalid point encoding")
+)
+
+// RandomScalar 从随机源 rand 读取，在 [0, order) 中均匀选取随机标量；rand 的错误原样返回
+func RandomScalar(rand io.Reader) (*big.Int, error) {
+	curve := twistededwards.GetEdwardsCurve()
+	return crand.Int(rand, &curve.Order)
+}
+
+// HashToScalar 以 domain 作域分隔，对 data 逐项（带长度前缀）哈希后模群阶
+func HashToScalar(domain string, data ...[]byte) *big.Int {
+	curve := twistededwards.GetEdwardsCurve()
+	hash := sha256.New()
+	writeLengthPrefixed(hash, []byte(domain))
+	for _, d := range data {
+		writeLengthPrefixed(hash, d)
+	}
+	res := new(big.Int).SetBytes(hash.Sum(nil))
+	return res.Mod(res, &curve.Order)
+}
+
+func writeLengthPrefixed(w io.Writer, b []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+	w.Write(l[:])
+	w.Write(b)
+}
+
+// ModOrder 返回 k mod order 的新值
+func ModOrder(k *big.Int) *big.Int {
+	curve := twistededwards.GetEdwardsCurve()
+	return new(big.Int).Mod(k, &curve.Order)
+}
+
+// ScalarBytes 将标量（先模群阶）编码为定长大端字节
+func ScalarBytes(k *big.Int) [ScalarSize]byte {
+	var res [ScalarSize]byte
+	ModOrder(k).FillBytes(res[:])
+	return res
+}
+
+// ScalarFromBytes 解码定长大端标量，拒绝非规范（>= order）的编码
+func ScalarFromBytes(b []byte) (*big.Int, error) {
+	curve := twistededwards.GetEdwardsCurve()
+	if len(b) != ScalarSize {
+		return nil, ErrScalarEncoding
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(&curve.Order) >= 0 {
+		return nil, ErrScalarEncoding
+	}
+	return k, nil
+}
+
+// PointFromBytes 解码压缩点，并检查点在曲线上且属于素数阶子群
+func PointFromBytes(b []byte) (twistededwards.PointAffine, error) {
+	var p twistededwards.PointAffine
+	if len(b) != PointSize {
+		return p, ErrPointEncoding
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, ErrPointEncoding
+	}
+	if !p.IsOnCurve() || !InSubgroup(&p) {
+		return p, ErrPointEncoding
+	}
+	// 拒绝非规范编码
+	if enc := p.Bytes(); string(enc[:]) != string(b) {
+		return p, ErrPointEncoding
+	}
+	return p, nil
+}
+
+// InSubgroup 判断 p 是否属于素数阶子群
+func InSubgroup(p *twistededwards.PointAffine) bool {
+	curve := twistededwards.GetEdwardsCurve()
+	var q twistededwards.PointAffine
+	q.ScalarMultiplication(p, &curve.Order)
+	return q.IsZero()
+}
+
+// keyImageDomain 一次性标志基点 H_p(P) 的域分隔标签
+const keyImageDomain = "LYcode/KeyImage/v1"
+
+// KeyImageBase 返回公钥 P 的一次性标志基点 H_p(P)，私钥 x 的一次性标志为 x·H_p(P)。
+// 环签名与储备证明共用同一映射，花费与计入储备时公开的标志才能相互比对。
+func KeyImageBase(P *twistededwards.PointAffine) twistededwards.PointAffine {
+	return HashToPoint(keyImageDomain, P.Marshal())
+}
+
+// HashToPoint 以 try-and-increment 方式将 (domain, data) 映射为素数阶子群中的点，离散对数未知
+func HashToPoint(domain string, data ...[]byte) twistededwards.PointAffine {
+	curve := twistededwards.GetEdwardsCurve()
+	var cofactor big.Int
+	curve.Cofactor.BigInt(&cofactor)
+	for counter := uint64(0); ; counter++ {
+		hash := sha256.New()
+		writeLengthPrefixed(hash, []byte(domain))
+		for _, d := range data {
+			writeLengthPrefixed(hash, d)
+		}
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], counter)
+		hash.Write(ctr[:])
+
+		var p twistededwards.PointAffine
+		if _, err := p.SetBytes(hash.Sum(nil)); err != nil || !p.IsOnCurve() {
+			continue
+		}
+		p.ScalarMultiplication(&p, &cofactor)
+		if !p.IsZero() {
+			return p
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package curveutil
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// Identity 返回单位元 (0, 1)
+func Identity() twistededwards.PointAffine {
+	var p twistededwards.PointAffine
+	p.X.SetZero()
+	p.Y.SetOne()
+	return p
+}
+
+// identityExtended 返回扩展坐标下的单位元
+func identityExtended() twistededwards.PointExtended {
+	var p twistededwards.PointExtended
+	p.X.SetZero()
+	p.Y.SetOne()
+	p.Z.SetOne()
+	p.T.SetZero()
+	return p
+}
+
+// MultiScalarMul 用 Pippenger 桶方法计算 Σ scalars[i]·points[i]，标量先模群阶
+func MultiScalarMul(points []twistededwards.PointAffine, scalars []*big.Int) twistededwards.PointAffine {
+	if len(points) != len(scalars) {
+		panic("curveutil: points and scalars length mismatch")
+	}
+	n := len(points)
+	if n == 0 {
+		return Identity()
+	}
+
+	curve := twistededwards.GetEdwardsCurve()
+	ks := make([]big.Int, n)
+	for i := range scalars {
+		ks[i].Mod(scalars[i], &curve.Order)
+	}
+
+	// 窗口宽度随点数增长
+	c := 2
+	if l := bits.Len(uint(n)); l > 5 {
+		c = l - 3
+	}
+	numBits := curve.Order.BitLen()
+	numWindows := (numBits + c - 1) / c
+
+	extended := make([]twistededwards.PointExtended, n)
+	for i := range points {
+		extended[i].FromAffine(&points[i])
+	}
+
+	buckets := make([]twistededwards.PointExtended, 1<<c)
+	res := identityExtended()
+	for w := numWindows - 1; w >= 0; w-- {
+		for i := 0; i < c; i++ {
+			res.Double(&res)
+		}
+		for b := range buckets {
+			buckets[b] = identityExtended()
+		}
+		for i := range ks {
+			idx := window(&ks[i], w*c, c)
+			if idx != 0 {
+				buckets[idx].Add(&buckets[idx], &extended[i])
+			}
+		}
+		// Σ b·bucket[b] = Σ_{b} (bucket[top] + ... + bucket[b])
+		running := identityExtended()
+		sum := identityExtended()
+		for b := len(buckets) - 1; b > 0; b-- {
+			running.Add(&running, &buckets[b])
+			sum.Add(&sum, &running)
+		}
+		res.Add(&res, &sum)
+	}
+
+	var out twistededwards.PointAffine
+	out.FromExtended(&res)
+	return out
+}
+
+// window 取 k 从第 start 位开始的 c 位
+func window(k *big.Int, start, c int) int {
+	res := 0
+	for i := c - 1; i >= 0; i-- {
+		res = res<<1 | int(k.Bit(start+i))
+	}
+	return res
+}
@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"io"
 	"math/big"
+	"net"
+
+	"MissionYang/ConfAccount"
+	"MissionYang/ConfAmount"
+	"MissionYang/OneTimeAddr"
+	"MissionYang/RangeProof"
+	"MissionYang/RecoverM2"
+	"MissionYang/ReserveProof"
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
 )
 
-func randomGenerator() (twistededwards.PointAffine, error) {
+func randomGenerator(rand io.Reader) (twistededwards.PointAffine, error) {
 	curve := twistededwards.GetEdwardsCurve()
-	r, err := rand.Int(rand.Reader, &curve.Order)
+	r, err := curveutil.RandomScalar(rand)
 	if err != nil {
 		return twistededwards.PointAffine{}, err
 	}
@@ -19,25 +31,73 @@ func randomGenerator() (twistededwards.PointAffine, error) {
 	return randGenerator, nil
 }
 
+// runSession 在一对连接上并发执行 rel 的交互式证明者与验证者，返回验证者的结论
+func runSession(rand io.Reader, rel *sigmaproof.Relation, witness []*big.Int, proverConn, verifierConn net.Conn) error {
+	prover, err := rel.NewProverSession(rand, proverConn, witness)
+	if err != nil {
+		return err
+	}
+	errc := make(chan error, 1)
+	go func() {
+		defer proverConn.Close()
+		errc <- prover.Run()
+	}()
+	err = rel.NewVerifierSession(verifierConn).Run()
+	verifierConn.Close()
+	if perr := <-errc; err == nil {
+		err = perr
+	}
+	return err
+}
+
+// amountCipher 接收方公钥 P 下的金额密文 X = r·P，Y = r·G + m·h
+type amountCipher struct {
+	P, X, Y twistededwards.PointAffine
+}
+
+// zkp2Relation 以 (r1, r2, r3, m1, m2, m3) 为秘密标量声明 ZKP2 的关系：cts 依次为输出 1–3
+// 与监管副本，每个密文满足 X = r·P，Y = r·G + m·h，监管副本共用 r2、m2
+func zkp2Relation(G, h twistededwards.PointAffine, cts [4]amountCipher) *sigmaproof.Relation {
+	rel := sigmaproof.New("LYcode/ZKP2/v1")
+	gG, gH := rel.Point(G), rel.Point(h)
+	sR1, sR2, sR3 := rel.Scalar(), rel.Scalar(), rel.Scalar()
+	sM1, sM2, sM3 := rel.Scalar(), rel.Scalar(), rel.Scalar()
+	rs := [4]sigmaproof.Scalar{sR1, sR2, sR3, sR2}
+	ms := [4]sigmaproof.Scalar{sM1, sM2, sM3, sM2}
+	for i, ct := range cts {
+		rel.Equation(rel.Point(ct.X), sigmaproof.Term{X: rs[i], P: rel.Point(ct.P)})
+		rel.Equation(rel.Point(ct.Y), sigmaproof.Term{X: rs[i], P: gG}, sigmaproof.Term{X: ms[i], P: gH})
+	}
+	return rel
+}
+
+// mustScalar 从 rand 读取随机标量，随机源出错时终止演示
+func mustScalar(rand io.Reader) *big.Int {
+	k, err := curveutil.RandomScalar(rand)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
 func main() {
 	// 1. 公共参数
 	curve := twistededwards.GetEdwardsCurve()
+	// 随机源：所有加密、签名与证明都从这里读取随机数，换成确定性的 io.Reader 即可复现测试向量
+	random := rand.Reader
 
 	// 2. 生成公私钥对
 	// 接收方
-	sk_r, err := rand.Int(rand.Reader, &curve.Order)
-	if err != nil {
-		panic(err)
-	}
+	sk_r := mustScalar(random)
 	var pk_r twistededwards.PointAffine
 	pk_r.ScalarMultiplication(&curve.Base, sk_r)
 	// 监管方
-	sk_rev, _ := rand.Int(rand.Reader, &curve.Order)
+	sk_rev := mustScalar(random)
 	var pk_rev twistededwards.PointAffine
 	pk_rev.ScalarMultiplication(&curve.Base, sk_rev)
 
 	// 3. 生成一次性地址
-	r_t, _ := rand.Int(rand.Reader, &curve.Order)
+	r_t := mustScalar(random)
 	var Rt, pk_r_rt, ota twistededwards.PointAffine
 	Rt.ScalarMultiplication(&curve.Base, r_t)
 	pk_r_rt.ScalarMultiplication(&pk_r, r_t)
@@ -49,69 +109,28 @@ func main() {
 	ota.Add(&ota, &pk_r)
 
 	// 4. 加密接收方地址
-	u, _ := rand.Int(rand.Reader, &curve.Order)
+	u := mustScalar(random)
 	var C1, C2 twistededwards.PointAffine
 	C1.ScalarMultiplication(&curve.Base, u)
 	C2.ScalarMultiplication(&pk_rev, u)
 	C2.Add(&C2, &pk_r)
 
 	// 5. ZkAddrProofGen
-	r_u, _ := rand.Int(rand.Reader, &curve.Order)
-	r_t, _ = rand.Int(rand.Reader, &curve.Order)
-	var Q1, Q2, ind twistededwards.PointAffine
-	Q1.ScalarMultiplication(&curve.Base, r_u)
-	Q2.ScalarMultiplication(&pk_rev, r_u)
-	ind.ScalarMultiplication(&curve.Base, new(big.Int).Neg(r_t))
-	Q2.Add(&Q2, &ind)
-
-	hash.Reset()
-	hash.Write(curve.Base.Marshal())
-	hash.Write(ota.Marshal())
-	hash.Write(pk_rev.Marshal())
-	hash.Write(C1.Marshal())
-	hash.Write(C2.Marshal())
-	hash.Write(Q1.Marshal())
-	hash.Write(Q2.Marshal())
-	hOut := new(big.Int).SetBytes(hash.Sum(nil))
-
-	var w1, wt big.Int
-	w1.Mul(hOut, u)
-	w1.Add(&w1, r_u)
-	wt.Mul(hOut, t)
-	wt.Add(&wt, r_t)
+	addrParams := onetimeaddr.Params{G: curve.Base, PkRev: pk_rev}
+	addrStmt := onetimeaddr.Statement{Ota: ota, C1: C1, C2: C2}
+	addrProof, err := onetimeaddr.ProveAddr(random, &addrParams, &onetimeaddr.Witness{Statement: addrStmt, U: u, T: t})
+	if err != nil {
+		panic(err)
+	}
 
 	// 6. ZkAddrProofVer
-	var Q1_, Q1_ind, Q2_, Q2_ind, Q2_ind1, Q2_ind2 twistededwards.PointAffine
-	Q1_.ScalarMultiplication(&curve.Base, &w1)
-	Q1_ind.ScalarMultiplication(&C1, new(big.Int).Neg(hOut))
-	Q1_.Add(&Q1_, &Q1_ind)
-
-	Q2_ind.ScalarMultiplication(&pk_rev, &w1)
-	Q2_ind1.ScalarMultiplication(&curve.Base, new(big.Int).Neg(&wt))
-	Q2_ind.Add(&Q2_ind, &Q2_ind1)
-
-	Q2_ind2.Neg(&C2)
-	Q2_ind2.Add(&ota, &Q2_ind2)
-	Q2_.ScalarMultiplication(&Q2_ind2, hOut)
-	Q2_.Add(&Q2_, &Q2_ind)
-
-	hash.Reset()
-	hash.Write(curve.Base.Marshal())
-	hash.Write(ota.Marshal())
-	hash.Write(pk_rev.Marshal())
-	hash.Write(C1.Marshal())
-	hash.Write(C2.Marshal())
-	hash.Write(Q1_.Marshal())
-	hash.Write(Q2_.Marshal())
-	h_ := new(big.Int).SetBytes(hash.Sum(nil))
-
-	if h_.Cmp(hOut) == 0 {
+	if err := onetimeaddr.VerifyAddr(&addrParams, &addrStmt, addrProof); err == nil {
 		fmt.Println("ZKP success!")
 	}
 
 	// 7. 一次性地址验证
 	// // 随机用户
-	sk_u, _ := rand.Int(rand.Reader, &curve.Order)
+	sk_u := mustScalar(random)
 	var pk_u twistededwards.PointAffine
 	pk_u.ScalarMultiplication(&curve.Base, sk_u)
 	var Rt_sk_u twistededwards.PointAffine
@@ -156,24 +175,36 @@ func main() {
 	if otaPubKey.Equal(&pk_r) {
 		fmt.Println("Recover successfully :)")
 	}
+	// // 可验证恢复：任何人可用 pk_rev 检查监管方给出的 pk_r
+	recoveredPk, recoveryProof, err := onetimeaddr.Recover(random, &addrParams, sk_rev, &addrStmt)
+	if err != nil {
+		panic(err)
+	}
+	if recoveredPk.Equal(&pk_r) && onetimeaddr.VerifyRecover(&addrParams, &addrStmt, &recoveredPk, recoveryProof) == nil &&
+		onetimeaddr.VerifyRecover(&addrParams, &addrStmt, &pk_u, recoveryProof) != nil {
+		fmt.Println("VerifiableRecover success!")
+	}
 
 	// 10. 交易金额加密算法
 	// // 参数初始化
-	p1, _ := rand.Int(rand.Reader, &curve.Order)
-	p2, _ := rand.Int(rand.Reader, &curve.Order)
-	p3, _ := rand.Int(rand.Reader, &curve.Order)
-	pu, _ := rand.Int(rand.Reader, &curve.Order)
+	p1 := mustScalar(random)
+	p2 := mustScalar(random)
+	p3 := mustScalar(random)
+	pu := mustScalar(random)
 	var P1, P2, P3, Pu twistededwards.PointAffine
 	P1.ScalarMultiplication(&curve.Base, p1)
 	P2.ScalarMultiplication(&curve.Base, p2)
 	P3.ScalarMultiplication(&curve.Base, p3)
 	Pu.ScalarMultiplication(&curve.Base, pu)
-	h, _ := randomGenerator()
+	h, err := randomGenerator(random)
+	if err != nil {
+		panic(err)
+	}
 
 	// 加密
-	r1, _ := rand.Int(rand.Reader, &curve.Order)
-	r2, _ := rand.Int(rand.Reader, &curve.Order)
-	r3, _ := rand.Int(rand.Reader, &curve.Order)
+	r1 := mustScalar(random)
+	r2 := mustScalar(random)
+	r3 := mustScalar(random)
 	m1 := big.NewInt(20)
 	m2 := big.NewInt(17)
 	m3 := big.NewInt(3)
@@ -199,135 +230,15 @@ func main() {
 	YInd.ScalarMultiplication(&h, m2)
 	Yu.Add(&Yu, &YInd)
 
-	// 11. 交易金额加密零知识证明算法
-	r1_, _ := rand.Int(rand.Reader, &curve.Order)
-	r2_, _ := rand.Int(rand.Reader, &curve.Order)
-	r3_, _ := rand.Int(rand.Reader, &curve.Order)
-	m1_, _ := rand.Int(rand.Reader, &curve.Order)
-	m2_, _ := rand.Int(rand.Reader, &curve.Order)
-	m3_, _ := rand.Int(rand.Reader, &curve.Order)
-
-	var X1_, X2_, X3_, Xu_, Y1_, Y2_, Y3_, Yu_, YInd_ twistededwards.PointAffine
-	X1_.ScalarMultiplication(&P1, r1_)
-	X2_.ScalarMultiplication(&P2, r2_)
-	X3_.ScalarMultiplication(&P3, r3_)
-	Xu_.ScalarMultiplication(&Pu, r2_)
-
-	Y1_.ScalarMultiplication(&curve.Base, r1_)
-	YInd_.ScalarMultiplication(&h, m1_)
-	Y1_.Add(&Y1_, &YInd_)
-
-	Y2_.ScalarMultiplication(&curve.Base, r2_)
-	YInd_.ScalarMultiplication(&h, m2_)
-	Y2_.Add(&Y2_, &YInd_)
-
-	Y3_.ScalarMultiplication(&curve.Base, r3_)
-	YInd_.ScalarMultiplication(&h, m3_)
-	Y3_.Add(&Y3_, &YInd_)
-
-	Yu_.ScalarMultiplication(&curve.Base, r2_)
-	YInd_.ScalarMultiplication(&h, m2_)
-	Yu_.Add(&Yu_, &YInd_)
-
-	hash.Reset()
-	hash.Write(X1.Marshal())
-	hash.Write(X2.Marshal())
-	hash.Write(X3.Marshal())
-	hash.Write(Xu.Marshal())
-	hash.Write(Y1.Marshal())
-	hash.Write(Y2.Marshal())
-	hash.Write(Y3.Marshal())
-	hash.Write(Yu.Marshal())
-	hash.Write(X1_.Marshal())
-	hash.Write(X2_.Marshal())
-	hash.Write(X3_.Marshal())
-	hash.Write(Xu_.Marshal())
-	hash.Write(Y1_.Marshal())
-	hash.Write(Y2_.Marshal())
-	hash.Write(Y3_.Marshal())
-	hash.Write(Yu_.Marshal())
-	hOut = new(big.Int).SetBytes(hash.Sum(nil))
-
-	var s1, s2, s3, sm1, sm2, sm3 big.Int
-	s1.Mul(hOut, r1)
-	s1.Add(&s1, r1_)
-
-	s2.Mul(hOut, r2)
-	s2.Add(&s2, r2_)
-
-	s3.Mul(hOut, r3)
-	s3.Add(&s3, r3_)
-
-	sm1.Mul(hOut, m1)
-	sm1.Add(&sm1, m1_)
-
-	sm2.Mul(hOut, m2)
-	sm2.Add(&sm2, m2_)
-
-	sm3.Mul(hOut, m3)
-	sm3.Add(&sm3, m3_)
+	// 11. 交易金额加密零知识证明算法：X_i = r_i·P_i，Y_i = r_i·G + m_i·h，监管副本共用 r2、m2
+	zkp2 := zkp2Relation(curve.Base, h, [4]amountCipher{{P1, X1, Y1}, {P2, X2, Y2}, {P3, X3, Y3}, {Pu, Xu, Yu}})
+	zkp2Proof, err := zkp2.Prove(random, []*big.Int{r1, r2, r3, m1, m2, m3})
+	if err != nil {
+		panic(err)
+	}
 
 	// 12. 验证
-	var X_1_, X_2_, X_3_, X_u_, X_Ind_, Y_1_, Y_2_, Y_3_, Y_u_, Y_Ind_, Y_Ind_1 twistededwards.PointAffine
-	X_1_.ScalarMultiplication(&P1, &s1)
-	X_Ind_.ScalarMultiplication(&X1, new(big.Int).Neg(hOut))
-	X_1_.Add(&X_1_, &X_Ind_)
-
-	X_2_.ScalarMultiplication(&P2, &s2)
-	X_Ind_.ScalarMultiplication(&X2, new(big.Int).Neg(hOut))
-	X_2_.Add(&X_2_, &X_Ind_)
-
-	X_3_.ScalarMultiplication(&P3, &s3)
-	X_Ind_.ScalarMultiplication(&X3, new(big.Int).Neg(hOut))
-	X_3_.Add(&X_3_, &X_Ind_)
-
-	X_u_.ScalarMultiplication(&Pu, &s2)
-	X_Ind_.ScalarMultiplication(&Xu, new(big.Int).Neg(hOut))
-	X_u_.Add(&X_u_, &X_Ind_)
-
-	Y_1_.ScalarMultiplication(&curve.Base, &s1)
-	Y_Ind_.ScalarMultiplication(&h, &sm1)
-	Y_Ind_1.ScalarMultiplication(&Y1, new(big.Int).Neg(hOut))
-	Y_1_.Add(&Y_1_, &Y_Ind_)
-	Y_1_.Add(&Y_1_, &Y_Ind_1)
-
-	Y_2_.ScalarMultiplication(&curve.Base, &s2)
-	Y_Ind_.ScalarMultiplication(&h, &sm2)
-	Y_Ind_1.ScalarMultiplication(&Y2, new(big.Int).Neg(hOut))
-	Y_2_.Add(&Y_2_, &Y_Ind_)
-	Y_2_.Add(&Y_2_, &Y_Ind_1)
-
-	Y_3_.ScalarMultiplication(&curve.Base, &s3)
-	Y_Ind_.ScalarMultiplication(&h, &sm3)
-	Y_Ind_1.ScalarMultiplication(&Y3, new(big.Int).Neg(hOut))
-	Y_3_.Add(&Y_3_, &Y_Ind_)
-	Y_3_.Add(&Y_3_, &Y_Ind_1)
-
-	Y_u_.ScalarMultiplication(&curve.Base, &s2)
-	Y_Ind_.ScalarMultiplication(&h, &sm2)
-	Y_Ind_1.ScalarMultiplication(&Yu, new(big.Int).Neg(hOut))
-	Y_u_.Add(&Y_u_, &Y_Ind_)
-	Y_u_.Add(&Y_u_, &Y_Ind_1)
-
-	hash.Reset()
-	hash.Write(X1.Marshal())
-	hash.Write(X2.Marshal())
-	hash.Write(X3.Marshal())
-	hash.Write(Xu.Marshal())
-	hash.Write(Y1.Marshal())
-	hash.Write(Y2.Marshal())
-	hash.Write(Y3.Marshal())
-	hash.Write(Yu.Marshal())
-	hash.Write(X_1_.Marshal())
-	hash.Write(X_2_.Marshal())
-	hash.Write(X_3_.Marshal())
-	hash.Write(X_u_.Marshal())
-	hash.Write(Y_1_.Marshal())
-	hash.Write(Y_2_.Marshal())
-	hash.Write(Y_3_.Marshal())
-	hash.Write(Y_u_.Marshal())
-	hOut_ := new(big.Int).SetBytes(hash.Sum(nil))
-	if hOut_.Cmp(hOut) == 0 {
+	if zkp2.Verify(zkp2Proof) == nil {
 		fmt.Println("ZKP2 success!")
 	}
 
@@ -349,4 +260,242 @@ func main() {
 	if hm2Comp.Equal(&hm) && hm2Comp.Equal(&hm2) {
 		fmt.Println("BalanceDec success!")
 	}
+	// // 可验证解密：监管方给出 hm2 的解密证明，任何人可用 Pu 检查
+	amountParams := confamount.Params{G: curve.Base, H: h}
+	ctu := confamount.EncryptWithRandomness(&amountParams, &Pu, m2, r2)
+	hmProved, decProof, err := confamount.DecryptWithProof(random, &amountParams, pu, ctu)
+	if err != nil {
+		panic(err)
+	}
+	if hmProved.Equal(&hm2) && confamount.VerifyDecryption(&amountParams, &Pu, ctu, &hm2, decProof) == nil &&
+		confamount.VerifyDecryption(&amountParams, &Pu, ctu, &hm2Comp, decProof) == nil &&
+		confamount.VerifyDecryption(&amountParams, &P2, ctu, &hm2, decProof) != nil {
+		fmt.Println("VerifiableDec success!")
+	}
+
+	// 14. 金额加密模块与上述流程对照
+	ct2 := confamount.EncryptWithRandomness(&amountParams, &P2, m2, r2)
+	if !ct2.X.Equal(&X2) || !ct2.Y.Equal(&Y2) || !ctu.X.Equal(&Xu) || !ctu.Y.Equal(&Yu) {
+		panic("confamount: ciphertext mismatch")
+	}
+	hmPkg, err := confamount.DecryptToPoint(pu, ctu)
+	if err != nil {
+		panic(err)
+	}
+	ct3, _, err := confamount.Encrypt(random, &amountParams, &P2, m3)
+	if err != nil {
+		panic(err)
+	}
+	ctSum, _, err := confamount.ReRandomize(random, &amountParams, &P2, confamount.Add(ct2, ct3))
+	if err != nil {
+		panic(err)
+	}
+	mSum, errSum := confamount.Decrypt(&amountParams, p2, ctSum, 1<<10)
+	mDiff, errDiff := confamount.Decrypt(&amountParams, p2, confamount.Sub(ct2, ct3), 1<<10)
+	if hmPkg.Equal(&hm2) && errSum == nil && errDiff == nil && mSum == 20 && mDiff == 14 {
+		fmt.Println("AmountEnc success!")
+	}
+
+	// 15. 监管副本明文相等证明
+	// // 共享随机数：(X2, Y2) 与 (Xu, Yu)
+	sharedPks := []twistededwards.PointAffine{P2, Pu}
+	sharedCts := []confamount.Ciphertext{*ct2, *ctu}
+	eqProof, err := confamount.ProveEquality(random, &amountParams, sharedPks, sharedCts, m2, []*big.Int{r2})
+	if err != nil {
+		panic(err)
+	}
+	// // 独立随机数：输出 1 及其监管副本
+	indepPks := []twistededwards.PointAffine{P1, Pu}
+	indepCts, indepRs, err := confamount.EncryptEach(random, &amountParams, indepPks, m1)
+	if err != nil {
+		panic(err)
+	}
+	eqProof1, err := confamount.ProveEquality(random, &amountParams, indepPks, indepCts, m1, indepRs)
+	if err != nil {
+		panic(err)
+	}
+	if confamount.VerifyEquality(&amountParams, sharedPks, sharedCts, eqProof) == nil &&
+		confamount.VerifyEquality(&amountParams, indepPks, indepCts, eqProof1) == nil {
+		fmt.Println("EqualityProof success!")
+	}
+
+	// 16. 输出金额范围证明（聚合）
+	rangeParams, err := rangeproof.NewParams(curve.Base, h, 64, 4)
+	if err != nil {
+		panic(err)
+	}
+	rangeProof, err := rangeproof.Prove(random, rangeParams, []uint64{m1.Uint64(), m2.Uint64(), m3.Uint64()}, []*big.Int{r1, r2, r3})
+	if err != nil {
+		panic(err)
+	}
+	if rangeproof.Verify(rangeParams, []twistededwards.PointAffine{Y1, Y2, Y3}, rangeProof) == nil {
+		fmt.Println("RangeProof success!")
+	}
+
+	// 17. 交易平衡证明：输入 Y1（m1 = 20）= 输出 Y2（m2 = 17）+ Y3（m3 = 3）+ 手续费 0
+	inputs := []twistededwards.PointAffine{Y1}
+	outputs := []twistededwards.PointAffine{Y2, Y3}
+	balanceProof, err := confamount.ProveBalance(random, &amountParams, inputs, outputs, 0, []*big.Int{r1}, []*big.Int{r2, r3})
+	if err != nil {
+		panic(err)
+	}
+	if confamount.VerifyBalance(&amountParams, inputs, outputs, 0, balanceProof) == nil &&
+		confamount.VerifyBalance(&amountParams, inputs, outputs, 1, balanceProof) != nil {
+		fmt.Println("BalanceProof success!")
+	}
+
+	// 18. 监管方批量恢复金额：hm2、输出 1 的监管副本，以及一个超出审计区间的金额
+	hmIndep, err := confamount.DecryptToPoint(pu, &indepCts[1])
+	if err != nil {
+		panic(err)
+	}
+	var hmLarge twistededwards.PointAffine
+	hmLarge.ScalarMultiplication(&h, big.NewInt(1<<30))
+	auditTargets := []twistededwards.PointAffine{hm2, hmIndep, hmLarge}
+	audit, err := recoverm2.SolveBatch(context.Background(), h, auditTargets, big.NewInt(0), big.NewInt(1<<20), nil,
+		&recoverm2.Options{Algorithm: recoverm2.BSGS})
+	if err != nil {
+		panic(err)
+	}
+	if audit.Found[hm2].Cmp(m2) == 0 && audit.Found[hmIndep].Cmp(m1) == 0 && audit.Failed[hmLarge] == recoverm2.ErrNotFound {
+		fmt.Println("BatchRecover success!")
+	}
+
+	// 19. 分块加密：输出 2 的金额按 16 位分块加密给监管方，证明与承诺 Y2 一致，逐块查表解密
+	chunkedCt, limbRs, err := confamount.EncryptChunked(random, &amountParams, &Pu, m2.Uint64())
+	if err != nil {
+		panic(err)
+	}
+	limbRangeParams, err := confamount.NewLimbRangeParams(&amountParams)
+	if err != nil {
+		panic(err)
+	}
+	chunkedProof, err := confamount.ProveChunked(random, &amountParams, limbRangeParams, &Pu, &Y2, r2, m2.Uint64(), chunkedCt, limbRs)
+	if err != nil {
+		panic(err)
+	}
+	limbTable := confamount.NewLimbTable(&amountParams)
+	mChunked, err := confamount.DecryptChunked(&amountParams, pu, chunkedCt, limbTable)
+	if confamount.VerifyChunked(&amountParams, limbRangeParams, &Pu, &Y2, chunkedCt, chunkedProof) == nil &&
+		err == nil && mChunked == m2.Uint64() {
+		fmt.Println("ChunkedAmount success!")
+	}
+
+	// 20. 账户模型：P1 存入 100 后向 P2 保密转账 m2，双方余额同态更新
+	accountParams, err := confaccount.NewParams(amountParams)
+	if err != nil {
+		panic(err)
+	}
+	ledger := confaccount.NewLedger(accountParams)
+	if err := ledger.Register(P1); err != nil {
+		panic(err)
+	}
+	if err := ledger.Register(P2); err != nil {
+		panic(err)
+	}
+	if err := ledger.Deposit(P1, 100); err != nil {
+		panic(err)
+	}
+	sender, _ := ledger.Account(P1)
+	transfer, err := confaccount.NewTransfer(random, accountParams, p1, &sender.Balance, 100, &P2, m2.Uint64(), sender.Nonce)
+	if err != nil {
+		panic(err)
+	}
+	errApply := ledger.Apply(transfer)
+	errReplay := ledger.Apply(transfer)
+	sender, _ = ledger.Account(P1)
+	receiver, _ := ledger.Account(P2)
+	mSender, _ := confamount.Decrypt(&amountParams, p1, &sender.Balance, 1<<10)
+	mReceiver, _ := confamount.Decrypt(&amountParams, p2, &receiver.Balance, 1<<10)
+	if errApply == nil && errReplay != nil && mSender == 100-m2.Uint64() && mReceiver == m2.Uint64() {
+		fmt.Println("ConfAccount success!")
+	}
+
+	// 21. 选择性披露：接收方不交出 p2，向审计方披露输出 2 的金额
+	auditContext := []byte("audit-001")
+	disclosure, err := confamount.DiscloseAmount(random, &amountParams, p2, ct2, m2.Uint64(), auditContext)
+	if err != nil {
+		panic(err)
+	}
+	forged := *disclosure
+	forged.M++
+	if confamount.VerifyDisclosure(&amountParams, &P2, ct2, disclosure, auditContext) == nil &&
+		confamount.VerifyDisclosure(&amountParams, &P2, ct2, &forged, auditContext) != nil &&
+		confamount.VerifyDisclosure(&amountParams, &P2, ct2, disclosure, []byte("audit-002")) != nil {
+		fmt.Println("AmountDisclosure success!")
+	}
+
+	// 22. 付款证明：发送方用 r_t 向仲裁方证明 ota 付给 pk_r，并披露输出 2 的金额
+	paymentOutput := onetimeaddr.AmountOutput{Params: &amountParams, PK: P2, Ct: *ct2}
+	paymentAmount := &onetimeaddr.PaymentAmount{AmountOutput: paymentOutput, M: m2.Uint64(), R: r2}
+	paymentProof, err := onetimeaddr.ProvePayment(random, &addrParams, r_t, &pk_r, &ota, paymentAmount, []byte("dispute-001"))
+	if err != nil {
+		panic(err)
+	}
+	if onetimeaddr.VerifyPayment(&addrParams, &Rt, &pk_r, &ota, &paymentOutput, paymentProof, []byte("dispute-001")) == nil &&
+		onetimeaddr.VerifyPayment(&addrParams, &Rt, &pk_u, &ota, &paymentOutput, paymentProof, []byte("dispute-001")) != nil &&
+		onetimeaddr.VerifyPayment(&addrParams, &Rt, &pk_r, &ota, nil, paymentProof, []byte("dispute-001")) != nil &&
+		paymentProof.Amount.M == m2.Uint64() {
+		fmt.Println("PaymentProof success!")
+	}
+
+	// 23. 储备证明：ota（金额承诺 Y2）混在其他输出中，证明者不暴露是哪一个，公开金额之和
+	reserveParams := reserveproof.NewParams(amountParams)
+	reserveRing := []reserveproof.Output{
+		{Ota: pk_u, Commitment: Y1},
+		{Ota: ota, Commitment: Y2},
+		{Ota: P3, Commitment: Y3},
+	}
+	otaKey := new(big.Int).Add(t, sk_r)
+	reserveKeys := []reserveproof.Key{{Index: 1, X: otaKey, M: m2.Uint64(), R: r2}}
+	reserve, err := reserveproof.Prove(random, reserveParams, reserveRing, reserveKeys, []byte("height-100"))
+	if err != nil {
+		panic(err)
+	}
+	// 花费 ota 时环签名公开一次性标志 x·H_p(ota)，它与储备证明中的链接标签相同
+	spendTag := curveutil.KeyImageBase(&ota)
+	spendTag.ScalarMultiplication(&spendTag, otaKey)
+	spentTags := map[twistededwards.PointAffine]bool{spendTag: true}
+	if reserveproof.Verify(reserveParams, reserveRing, reserve, nil, []byte("height-100")) == nil &&
+		reserveproof.Verify(reserveParams, reserveRing, reserve, spentTags, []byte("height-100")) == reserveproof.ErrSpentOutput &&
+		reserve.Total == m2.Uint64() {
+		fmt.Println("ReserveProof success!")
+	}
+
+	// 24. 析取证明：知道 {pk_u, ota, P3} 中某一个地址的私钥；ZkAddrProof 对两个陈述之一成立
+	orOtas := []twistededwards.PointAffine{pk_u, ota, P3}
+	keyOrProof, err := onetimeaddr.ProveKeyOr(random, &addrParams, orOtas, 1, otaKey, []byte("compliance-001"))
+	if err != nil {
+		panic(err)
+	}
+	decoyStmt := onetimeaddr.Statement{Ota: pku_, C1: C1, C2: C2}
+	orStmts := []onetimeaddr.Statement{decoyStmt, addrStmt}
+	addrOrProof, err := onetimeaddr.ProveAddrOr(random, &addrParams, orStmts, 1, &onetimeaddr.Witness{Statement: addrStmt, U: u, T: t})
+	if err != nil {
+		panic(err)
+	}
+	if onetimeaddr.VerifyKeyOr(&addrParams, orOtas, keyOrProof, []byte("compliance-001")) == nil &&
+		onetimeaddr.VerifyKeyOr(&addrParams, orOtas[:2], keyOrProof, []byte("compliance-001")) != nil &&
+		onetimeaddr.VerifyAddrOr(&addrParams, orStmts, addrOrProof) == nil {
+		fmt.Println("OrProof success!")
+	}
+
+	// 25. 诚实验证者零知识：ZkAddrProof 与 ZKP2 的模拟副本与真实副本在统计上不可区分
+	addrCmp, err := onetimeaddr.AddrRelation(&addrParams, &addrStmt).CompareSimulator(random, []*big.Int{u, t}, 200)
+	if err != nil {
+		panic(err)
+	}
+	zkp2Cmp, err := zkp2.CompareSimulator(random, []*big.Int{r1, r2, r3, m1, m2, m3}, 200)
+	if err != nil {
+		panic(err)
+	}
+	if addrCmp.Indistinguishable() && zkp2Cmp.Indistinguishable() {
+		fmt.Println("HVZK success!")
+	}
+
+	// 26. 交互模式：ZkAddrProof 的证明者与验证者经 net.Pipe 交换承诺、挑战与响应
+	pipeP, pipeV := net.Pipe()
+	if runSession(random, onetimeaddr.AddrRelation(&addrParams, &addrStmt), []*big.Int{u, t}, pipeP, pipeV) == nil {
+		fmt.Println("Interactive success!")
+	}
 }
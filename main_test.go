@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"MissionYang/SigmaProof"
+	"MissionYang/internal/curveutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+)
+
+// 回卷 ZKP2 的证明者必须提取出三个输出的 (r_i, m_i)，监管副本共用 r2、m2
+func TestZKP2Extract(t *testing.T) {
+	curve := twistededwards.GetEdwardsCurve()
+	h, err := randomGenerator(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := []*big.Int{mustScalar(rand.Reader), mustScalar(rand.Reader), mustScalar(rand.Reader), big.NewInt(20), big.NewInt(17), big.NewInt(3)}
+	var cts [4]amountCipher
+	for i := range cts {
+		j := i
+		if i == 3 {
+			j = 1
+		}
+		r, m := witness[j], witness[3+j]
+		cts[i].P.ScalarMultiplication(&curve.Base, mustScalar(rand.Reader))
+		cts[i].X.ScalarMultiplication(&cts[i].P, r)
+		cts[i].Y = curveutil.MultiScalarMul([]twistededwards.PointAffine{curve.Base, h}, []*big.Int{r, m})
+	}
+
+	p, err := zkp2Relation(curve.Base, h, cts).NewProver(rand.Reader, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := sigmaproof.Rewind(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range witness {
+		if got[j].Cmp(witness[j]) != 0 {
+			t.Fatalf("scalar %d: got %v, want %v", j, got[j], witness[j])
+		}
+	}
+}